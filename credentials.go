@@ -0,0 +1,202 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/ec2rolecreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/endpointcreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+
+	"github.com/goravel/framework/contracts/config"
+)
+
+const (
+	// ecsContainerEndpoint is the host of the ECS credentials endpoint, used with AWS_CONTAINER_CREDENTIALS_RELATIVE_URI.
+	ecsContainerEndpoint = "http://169.254.170.2"
+	// assumeRoleExpiryWindow is how long before their expiration the credentials of an assumed role are refreshed.
+	assumeRoleExpiryWindow = time.Minute
+)
+
+// newCredentialsProvider returns the credentials provider of the disk, the base credentials are used to assume the
+// role of assume_role.role_arn when it's set. The shared configuration is the one returned by loadSharedConfig.
+func newCredentialsProvider(ctx context.Context, config config.Config, disk string, sharedConfig aws.Config) (aws.CredentialsProvider, error) {
+	region := sharedConfig.Region
+	provider, err := newBaseCredentialsProvider(ctx, config, disk, sharedConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	roleARN := config.GetString(fmt.Sprintf("filesystems.disks.%s.assume_role.role_arn", disk))
+	if roleARN == "" {
+		return provider, nil
+	}
+
+	externalID := config.GetString(fmt.Sprintf("filesystems.disks.%s.assume_role.external_id", disk))
+	sessionName := config.GetString(fmt.Sprintf("filesystems.disks.%s.assume_role.session_name", disk))
+	duration := config.GetInt(fmt.Sprintf("filesystems.disks.%s.assume_role.duration", disk))
+	mfaSerial := config.GetString(fmt.Sprintf("filesystems.disks.%s.assume_role.mfa_serial", disk))
+	endpoint := config.GetString(fmt.Sprintf("filesystems.disks.%s.assume_role.endpoint", disk))
+	// The token provider is required, reading the code from the standard input would block a server on every refresh.
+	mfaTokenProvider, _ := config.Get(fmt.Sprintf("filesystems.disks.%s.assume_role.mfa_token_provider", disk)).(func() (string, error))
+	if mfaSerial != "" && mfaTokenProvider == nil {
+		return nil, fmt.Errorf("the assume_role.mfa_serial of %s requires an assume_role.mfa_token_provider", disk)
+	}
+
+	client := sts.New(sts.Options{
+		Region:      region,
+		Credentials: provider,
+		HTTPClient:  sharedConfig.HTTPClient,
+	}, func(options *sts.Options) {
+		if endpoint != "" {
+			options.BaseEndpoint = aws.String(endpoint)
+		}
+	})
+	assumeRoleProvider := stscreds.NewAssumeRoleProvider(client, roleARN, func(options *stscreds.AssumeRoleOptions) {
+		options.RoleSessionName = sessionName
+		options.Duration = time.Duration(duration) * time.Second
+		if externalID != "" {
+			options.ExternalID = aws.String(externalID)
+		}
+		if mfaSerial != "" {
+			options.SerialNumber = aws.String(mfaSerial)
+			options.TokenProvider = mfaTokenProvider
+		}
+	})
+
+	// The credentials are refreshed a bit before they expire, so a request isn't signed with expiring credentials.
+	return aws.NewCredentialsCache(assumeRoleProvider, func(options *aws.CredentialsCacheOptions) {
+		options.ExpiryWindow = assumeRoleExpiryWindow
+	}), nil
+}
+
+// newBaseCredentialsProvider returns the provider chosen with the credentials key, which is either a provider name
+// or an aws.CredentialsProvider. Without it, the key and secret are used when they are set, otherwise the default
+// credential chain of the SDK is used, starting from the profile of the shared configuration when it's loaded.
+func newBaseCredentialsProvider(ctx context.Context, config config.Config, disk string, sharedConfig aws.Config) (aws.CredentialsProvider, error) {
+	region := sharedConfig.Region
+	accessKeyId := config.GetString(fmt.Sprintf("filesystems.disks.%s.key", disk))
+	accessKeySecret := config.GetString(fmt.Sprintf("filesystems.disks.%s.secret", disk))
+	token := config.GetString(fmt.Sprintf("filesystems.disks.%s.token", disk))
+
+	var name string
+	switch value := config.Get(fmt.Sprintf("filesystems.disks.%s.credentials", disk)).(type) {
+	case aws.CredentialsProvider:
+		return value, nil
+	case string:
+		name = value
+	}
+
+	if name == "" {
+		name = "default"
+		if accessKeyId != "" || accessKeySecret != "" {
+			name = "static"
+		}
+	}
+
+	switch name {
+	case "static":
+		if accessKeyId == "" || accessKeySecret == "" {
+			return nil, fmt.Errorf("please set the key and secret of %s configuration first", disk)
+		}
+
+		return aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider(accessKeyId, accessKeySecret, token)), nil
+	case "default":
+		if sharedConfig.Credentials != nil {
+			return sharedConfig.Credentials, nil
+		}
+
+		awsConfig, err := awsconfig.LoadDefaultConfig(ctx, defaultConfigOptions(sharedConfig)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the default credentials of %s: %w", disk, err)
+		}
+
+		return awsConfig.Credentials, nil
+	case "env":
+		envConfig, err := awsconfig.NewEnvConfig()
+		if err != nil {
+			return nil, err
+		}
+		if !envConfig.Credentials.HasKeys() {
+			return nil, fmt.Errorf("the env credentials of %s require AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY", disk)
+		}
+
+		return aws.NewCredentialsCache(credentials.StaticCredentialsProvider{Value: envConfig.Credentials}), nil
+	case "web_identity":
+		envConfig, err := awsconfig.NewEnvConfig()
+		if err != nil {
+			return nil, err
+		}
+		if envConfig.WebIdentityTokenFilePath == "" || envConfig.RoleARN == "" {
+			return nil, fmt.Errorf("the web_identity credentials of %s require AWS_WEB_IDENTITY_TOKEN_FILE and AWS_ROLE_ARN", disk)
+		}
+
+		client := sts.New(sts.Options{Region: region, HTTPClient: sharedConfig.HTTPClient})
+		provider := stscreds.NewWebIdentityRoleProvider(client, envConfig.RoleARN, stscreds.IdentityTokenFile(envConfig.WebIdentityTokenFilePath), func(options *stscreds.WebIdentityRoleOptions) {
+			options.RoleSessionName = envConfig.RoleSessionName
+		})
+
+		return aws.NewCredentialsCache(provider), nil
+	case "container":
+		envConfig, err := awsconfig.NewEnvConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		endpoint := envConfig.ContainerCredentialsEndpoint
+		if envConfig.ContainerCredentialsRelativePath != "" {
+			endpoint = ecsContainerEndpoint + envConfig.ContainerCredentialsRelativePath
+		}
+		if endpoint == "" {
+			return nil, fmt.Errorf("the container credentials of %s require AWS_CONTAINER_CREDENTIALS_RELATIVE_URI or AWS_CONTAINER_CREDENTIALS_FULL_URI", disk)
+		}
+
+		provider := endpointcreds.New(endpoint, func(options *endpointcreds.Options) {
+			options.AuthorizationToken = envConfig.ContainerAuthorizationToken
+			if tokenFile := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"); tokenFile != "" {
+				options.AuthorizationTokenProvider = endpointcreds.TokenProviderFunc(func() (string, error) {
+					token, err := os.ReadFile(tokenFile)
+
+					return string(token), err
+				})
+			}
+		})
+
+		return aws.NewCredentialsCache(provider), nil
+	case "imds":
+		var err error
+		// The configuration is loaded to honour the IMDS settings of the environment, such as the endpoint.
+		awsConfig := sharedConfig
+		if awsConfig.Credentials == nil {
+			if awsConfig, err = awsconfig.LoadDefaultConfig(ctx, defaultConfigOptions(sharedConfig)...); err != nil {
+				return nil, err
+			}
+		}
+
+		provider := ec2rolecreds.New(func(options *ec2rolecreds.Options) {
+			options.Client = imds.NewFromConfig(awsConfig)
+		})
+
+		return aws.NewCredentialsCache(provider), nil
+	default:
+		return nil, fmt.Errorf("unsupported credentials %q of %s, it should be static, default, env, web_identity, container or imds", name, disk)
+	}
+}
+
+// defaultConfigOptions returns the options loading the default configuration with the region and the HTTP client of
+// the disk.
+func defaultConfigOptions(sharedConfig aws.Config) []func(*awsconfig.LoadOptions) error {
+	optFns := []func(*awsconfig.LoadOptions) error{awsconfig.WithRegion(sharedConfig.Region)}
+	if sharedConfig.HTTPClient != nil {
+		optFns = append(optFns, awsconfig.WithHTTPClient(sharedConfig.HTTPClient))
+	}
+
+	return optFns
+}
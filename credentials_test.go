@@ -0,0 +1,330 @@
+package s3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/stretchr/testify/assert"
+)
+
+// isolateCredentials clears the credentials of the environment, so only the ones set by a test are found.
+func isolateCredentials(t *testing.T) {
+	for _, name := range []string{
+		"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_PROFILE", "AWS_DEFAULT_PROFILE",
+		"AWS_ROLE_ARN", "AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_CONTAINER_CREDENTIALS_RELATIVE_URI",
+		"AWS_CONTAINER_CREDENTIALS_FULL_URI", "AWS_CONTAINER_AUTHORIZATION_TOKEN", "AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE",
+		"AWS_EC2_METADATA_DISABLED", "AWS_EC2_METADATA_SERVICE_ENDPOINT", "AWS_CA_BUNDLE",
+	} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+}
+
+// newFakeContainerCredentials starts an ECS credentials endpoint stand-in serving the given access key.
+func newFakeContainerCredentials(t *testing.T, accessKey, authorization string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != authorization {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"AccessKeyId":     accessKey,
+			"SecretAccessKey": "secret",
+			"Token":           "token",
+			"Expiration":      time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// newFakeIMDS starts an instance metadata service stand-in serving the given access key.
+func newFakeIMDS(t *testing.T, accessKey string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.Method == http.MethodPut && req.URL.Path == "/latest/api/token":
+			w.Header().Set("X-Aws-Ec2-Metadata-Token-Ttl-Seconds", req.Header.Get("X-Aws-Ec2-Metadata-Token-Ttl-Seconds"))
+			_, _ = w.Write([]byte("imds-token"))
+		case req.Header.Get("X-Aws-Ec2-Metadata-Token") != "imds-token":
+			w.WriteHeader(http.StatusUnauthorized)
+		case req.URL.Path == "/latest/meta-data/iam/security-credentials/":
+			_, _ = w.Write([]byte("goravel"))
+		case req.URL.Path == "/latest/meta-data/iam/security-credentials/goravel":
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"Code":            "Success",
+				"Type":            "AWS-HMAC",
+				"AccessKeyId":     accessKey,
+				"SecretAccessKey": "secret",
+				"Token":           "token",
+				"Expiration":      time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+				"LastUpdated":     time.Now().UTC().Format(time.RFC3339),
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// fakeSTS is an STS stand-in answering AssumeRole with credentials expiring after the given lifetime.
+type fakeSTS struct {
+	// accessKeys are the access keys that signed the requests.
+	accessKeys []string
+	lifetime   time.Duration
+	lock       sync.Mutex
+	requests   []url.Values
+	server     *httptest.Server
+}
+
+func newFakeSTS(t *testing.T, lifetime time.Duration) *fakeSTS {
+	fake := &fakeSTS{lifetime: lifetime}
+	fake.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := req.ParseForm(); err != nil || req.PostForm.Get("Action") != "AssumeRole" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		fake.lock.Lock()
+		fake.requests = append(fake.requests, req.PostForm)
+		_, credential, _ := strings.Cut(req.Header.Get("Authorization"), "Credential=")
+		accessKey, _, _ := strings.Cut(credential, "/")
+		fake.accessKeys = append(fake.accessKeys, accessKey)
+		number := len(fake.requests)
+		fake.lock.Unlock()
+
+		w.Header().Set("Content-Type", "text/xml")
+		_, _ = fmt.Fprintf(w, `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleResult>
+    <Credentials>
+      <AccessKeyId>assumed-key-%d</AccessKeyId>
+      <SecretAccessKey>secret</SecretAccessKey>
+      <SessionToken>token</SessionToken>
+      <Expiration>%s</Expiration>
+    </Credentials>
+    <AssumedRoleUser>
+      <Arn>%s/session</Arn>
+      <AssumedRoleId>AROA:session</AssumedRoleId>
+    </AssumedRoleUser>
+  </AssumeRoleResult>
+</AssumeRoleResponse>`, number, time.Now().Add(fake.lifetime).UTC().Format(time.RFC3339), req.PostForm.Get("RoleArn"))
+	}))
+	t.Cleanup(fake.server.Close)
+
+	return fake
+}
+
+func TestCredentials(t *testing.T) {
+	tests := []struct {
+		name      string
+		setup     func(t *testing.T)
+		values    map[string]any
+		accessKey string
+	}{
+		{
+			name:      "static key and secret",
+			accessKey: "key",
+		},
+		{
+			name: "default chain from the environment",
+			setup: func(t *testing.T) {
+				t.Setenv("AWS_ACCESS_KEY_ID", "env-key")
+				t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+			},
+			values:    map[string]any{"filesystems.disks.s3.key": "", "filesystems.disks.s3.secret": ""},
+			accessKey: "env-key",
+		},
+		{
+			name: "default chain from the container endpoint",
+			setup: func(t *testing.T) {
+				t.Setenv("AWS_CONTAINER_CREDENTIALS_FULL_URI", newFakeContainerCredentials(t, "container-key", "Bearer").URL)
+				t.Setenv("AWS_CONTAINER_AUTHORIZATION_TOKEN", "Bearer")
+			},
+			values:    map[string]any{"filesystems.disks.s3.key": "", "filesystems.disks.s3.secret": ""},
+			accessKey: "container-key",
+		},
+		{
+			name: "default chain from the instance metadata",
+			setup: func(t *testing.T) {
+				t.Setenv("AWS_EC2_METADATA_SERVICE_ENDPOINT", newFakeIMDS(t, "imds-key").URL)
+			},
+			values:    map[string]any{"filesystems.disks.s3.key": "", "filesystems.disks.s3.secret": ""},
+			accessKey: "imds-key",
+		},
+		{
+			name: "env is chosen over the key and secret",
+			setup: func(t *testing.T) {
+				t.Setenv("AWS_ACCESS_KEY_ID", "env-key")
+				t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+			},
+			values:    map[string]any{"filesystems.disks.s3.credentials": "env"},
+			accessKey: "env-key",
+		},
+		{
+			name: "container is chosen over the environment",
+			setup: func(t *testing.T) {
+				t.Setenv("AWS_ACCESS_KEY_ID", "env-key")
+				t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+				t.Setenv("AWS_CONTAINER_CREDENTIALS_FULL_URI", newFakeContainerCredentials(t, "container-key", "").URL)
+			},
+			values:    map[string]any{"filesystems.disks.s3.credentials": "container"},
+			accessKey: "container-key",
+		},
+		{
+			name: "imds is chosen over the environment",
+			setup: func(t *testing.T) {
+				t.Setenv("AWS_ACCESS_KEY_ID", "env-key")
+				t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+				t.Setenv("AWS_EC2_METADATA_SERVICE_ENDPOINT", newFakeIMDS(t, "imds-key").URL)
+			},
+			values:    map[string]any{"filesystems.disks.s3.credentials": "imds"},
+			accessKey: "imds-key",
+		},
+		{
+			name: "provider",
+			values: map[string]any{
+				"filesystems.disks.s3.credentials": credentials.NewStaticCredentialsProvider("provider-key", "secret", ""),
+			},
+			accessKey: "provider-key",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			isolateCredentials(t)
+			if test.setup != nil {
+				test.setup(t)
+			}
+
+			driver, fake := newFakeDriver(t, test.values)
+			assert.Nil(t, driver.Put("Credentials.txt", "Goravel"))
+			assert.Equal(t, test.accessKey, fake.accessKey)
+		})
+	}
+}
+
+func TestCredentials_AssumeRole(t *testing.T) {
+	isolateCredentials(t)
+	sts := newFakeSTS(t, time.Hour)
+	driver, fake := newFakeDriver(t, map[string]any{
+		"filesystems.disks.s3.assume_role.role_arn":     "arn:aws:iam::123456789012:role/goravel",
+		"filesystems.disks.s3.assume_role.external_id":  "external",
+		"filesystems.disks.s3.assume_role.session_name": "goravel",
+		"filesystems.disks.s3.assume_role.duration":     1800,
+		"filesystems.disks.s3.assume_role.mfa_serial":   "arn:aws:iam::123456789012:mfa/goravel",
+		"filesystems.disks.s3.assume_role.mfa_token_provider": func() (string, error) {
+			return "123456", nil
+		},
+		"filesystems.disks.s3.assume_role.endpoint": sts.server.URL,
+	})
+
+	assert.Nil(t, driver.Put("AssumeRole/1.txt", "Goravel"))
+	assert.Equal(t, "assumed-key-1", fake.accessKey)
+	assert.Nil(t, driver.Put("AssumeRole/2.txt", "Goravel"))
+	assert.Equal(t, "assumed-key-1", fake.accessKey)
+
+	// The credentials are cached until they expire.
+	assert.Len(t, sts.requests, 1)
+	assert.Equal(t, []string{"key"}, sts.accessKeys)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/goravel", sts.requests[0].Get("RoleArn"))
+	assert.Equal(t, "external", sts.requests[0].Get("ExternalId"))
+	assert.Equal(t, "goravel", sts.requests[0].Get("RoleSessionName"))
+	assert.Equal(t, "1800", sts.requests[0].Get("DurationSeconds"))
+	assert.Equal(t, "arn:aws:iam::123456789012:mfa/goravel", sts.requests[0].Get("SerialNumber"))
+	assert.Equal(t, "123456", sts.requests[0].Get("TokenCode"))
+}
+
+func TestCredentials_AssumeRoleRefresh(t *testing.T) {
+	isolateCredentials(t)
+	// The credentials expire within the expiry window, so they are refreshed before every request.
+	sts := newFakeSTS(t, assumeRoleExpiryWindow/2)
+	driver, fake := newFakeDriver(t, map[string]any{
+		"filesystems.disks.s3.assume_role.role_arn": "arn:aws:iam::123456789012:role/goravel",
+		"filesystems.disks.s3.assume_role.endpoint": sts.server.URL,
+	})
+
+	assert.Nil(t, driver.Put("AssumeRole.txt", "Goravel"))
+	assert.Equal(t, "assumed-key-1", fake.accessKey)
+	assert.Nil(t, driver.Put("AssumeRole.txt", "Goravel"))
+	assert.Equal(t, "assumed-key-2", fake.accessKey)
+	assert.Len(t, sts.requests, 2)
+	assert.Empty(t, sts.requests[0].Get("ExternalId"))
+	assert.Empty(t, sts.requests[0].Get("SerialNumber"))
+}
+
+func TestCredentials_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[string]any
+		err    string
+	}{
+		{
+			name:   "key without secret",
+			values: map[string]any{"filesystems.disks.s3.key": "key"},
+			err:    "please set the key and secret of s3 configuration first",
+		},
+		{
+			name:   "static without key",
+			values: map[string]any{"filesystems.disks.s3.credentials": "static"},
+			err:    "please set the key and secret of s3 configuration first",
+		},
+		{
+			name:   "env without environment",
+			values: map[string]any{"filesystems.disks.s3.credentials": "env"},
+			err:    "the env credentials of s3 require AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY",
+		},
+		{
+			name:   "container without endpoint",
+			values: map[string]any{"filesystems.disks.s3.credentials": "container"},
+			err:    "the container credentials of s3 require AWS_CONTAINER_CREDENTIALS_RELATIVE_URI or AWS_CONTAINER_CREDENTIALS_FULL_URI",
+		},
+		{
+			name: "mfa without token provider",
+			values: map[string]any{
+				"filesystems.disks.s3.key":                    "key",
+				"filesystems.disks.s3.secret":                 "secret",
+				"filesystems.disks.s3.assume_role.role_arn":   "arn:aws:iam::123456789012:role/goravel",
+				"filesystems.disks.s3.assume_role.mfa_serial": "arn:aws:iam::123456789012:mfa/goravel",
+			},
+			err: "the assume_role.mfa_serial of s3 requires an assume_role.mfa_token_provider",
+		},
+		{
+			name:   "unsupported",
+			values: map[string]any{"filesystems.disks.s3.credentials": "vault"},
+			err:    `unsupported credentials "vault" of s3, it should be static, default, env, web_identity, container or imds`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			isolateCredentials(t)
+
+			values := map[string]any{
+				"filesystems.disks.s3.region": "us-east-1",
+				"filesystems.disks.s3.bucket": "goravel",
+				"filesystems.disks.s3.url":    "https://goravel.dev",
+			}
+			for key, value := range test.values {
+				values[key] = value
+			}
+
+			driver, err := NewS3(context.Background(), newMockConfig(t, values), "s3")
+			assert.Nil(t, driver)
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package s3
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	// deleteBatchSize is the maximum number of keys of a DeleteObjects request.
+	deleteBatchSize = 1000
+
+	defaultDeleteConcurrency = 4
+)
+
+// ErrDeleteVetoed is returned when the delete guard of the disk vetoes a delete.
+var ErrDeleteVetoed = errors.New("delete vetoed by the guard")
+
+// DeleteGuard is called with the keys of a delete above the filesystems.disks.<disk>.delete_guard.threshold
+// key count, returning an error vetoes the delete. It's set by filesystems.disks.<disk>.delete_guard.callback.
+type DeleteGuard func(keys []string) error
+
+// DeleteError is returned when some keys can't be deleted, the other keys are deleted.
+type DeleteError struct {
+	Failures []DeleteFailure
+	// errs are the errors of the DeleteObjects requests that failed as a whole.
+	errs []error
+}
+
+// DeleteFailure describes why a key can't be deleted.
+type DeleteFailure struct {
+	Key     string
+	Code    string
+	Message string
+}
+
+func (r *DeleteError) Error() string {
+	const maxListed = 10
+
+	var failures []string
+	for _, failure := range r.Failures[:min(len(r.Failures), maxListed)] {
+		failures = append(failures, fmt.Sprintf("%s (%s: %s)", failure.Key, failure.Code, failure.Message))
+	}
+	if len(r.Failures) > maxListed {
+		failures = append(failures, fmt.Sprintf("and %d more", len(r.Failures)-maxListed))
+	}
+
+	return fmt.Sprintf("failed to delete %d keys: %s", len(r.Failures), strings.Join(failures, ", "))
+}
+
+func (r *DeleteError) Unwrap() []error {
+	return r.errs
+}
+
+// guardDelete calls the delete guard, if any, when the keys exceed the threshold.
+func (r *S3) guardDelete(keys []string) error {
+	if r.deleteGuard == nil || len(keys) <= r.deleteGuardThreshold {
+		return nil
+	}
+
+	if err := r.deleteGuard(keys); err != nil {
+		return fmt.Errorf("%w: %w", ErrDeleteVetoed, err)
+	}
+
+	return nil
+}
+
+// deleteObjects deletes the keys in batches of 1000.
+func (r *S3) deleteObjects(keys []string) error {
+	return r.deleteBatches(func(yield func([]string, error) bool) {
+		for batch := range slices.Chunk(keys, deleteBatchSize) {
+			if !yield(batch, nil) {
+				return
+			}
+		}
+	})
+}
+
+// deleteBatches deletes the batches with at most delete.concurrency requests in flight, every batch is
+// sent even if another one fails. The batches stop at the first error they yield.
+func (r *S3) deleteBatches(batches iter.Seq2[[]string, error]) error {
+	var (
+		batchesErr error
+		deleteErr  DeleteError
+		lock       sync.Mutex
+		wg         sync.WaitGroup
+	)
+
+	semaphore := make(chan struct{}, r.deleteConcurrency)
+	for batch, err := range batches {
+		if err != nil {
+			batchesErr = err
+			break
+		}
+		if len(batch) == 0 {
+			continue
+		}
+
+		semaphore <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			failures, err := r.deleteBatch(batch)
+
+			lock.Lock()
+			defer lock.Unlock()
+			deleteErr.Failures = append(deleteErr.Failures, failures...)
+			if err != nil {
+				deleteErr.errs = append(deleteErr.errs, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(deleteErr.Failures) == 0 {
+		return batchesErr
+	}
+
+	slices.SortFunc(deleteErr.Failures, func(a, b DeleteFailure) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	return errors.Join(batchesErr, &deleteErr)
+}
+
+// deleteBatch deletes up to 1000 keys, the keys failing with a retryable code, such as SlowDown, are sent again
+// with a jittered backoff until retry.max_attempts is reached.
+func (r *S3) deleteBatch(keys []string) ([]DeleteFailure, error) {
+	var failures []DeleteFailure
+	for attempt := 1; ; attempt++ {
+		attemptFailures, err := r.deleteKeys(keys)
+		if err != nil || attempt >= r.retry.maxAttempts {
+			return append(failures, attemptFailures...), err
+		}
+
+		var retried []string
+		for _, failure := range attemptFailures {
+			if r.retry.retryable(failure.Code) {
+				retried = append(retried, failure.Key)
+			} else {
+				failures = append(failures, failure)
+			}
+		}
+		if len(retried) == 0 {
+			return failures, nil
+		}
+		keys = retried
+
+		select {
+		case <-r.ctx.Done():
+			for _, key := range keys {
+				failures = append(failures, DeleteFailure{Key: key, Code: "RequestCanceled", Message: r.ctx.Err().Error()})
+			}
+
+			return failures, r.ctx.Err()
+		case <-time.After(r.retry.delay(attempt)):
+		}
+	}
+}
+
+// deleteKeys sends a DeleteObjects request, every key is reported as failed if the request fails.
+func (r *S3) deleteKeys(keys []string) ([]DeleteFailure, error) {
+	objectIdentifiers := make([]types.ObjectIdentifier, 0, len(keys))
+	for _, key := range keys {
+		objectIdentifiers = append(objectIdentifiers, types.ObjectIdentifier{
+			Key: aws.String(key),
+		})
+	}
+
+	resp, err := r.instance.DeleteObjects(r.ctx, &s3.DeleteObjectsInput{
+		Bucket: aws.String(r.bucket),
+		Delete: &types.Delete{
+			Objects: objectIdentifiers,
+			Quiet:   aws.Bool(true),
+		},
+	})
+	r.stats.forget(keys...)
+	if err != nil {
+		code := "RequestError"
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			code = apiErr.ErrorCode()
+		}
+
+		failures := make([]DeleteFailure, 0, len(keys))
+		for _, key := range keys {
+			failures = append(failures, DeleteFailure{Key: key, Code: code, Message: err.Error()})
+		}
+
+		return failures, err
+	}
+
+	failures := make([]DeleteFailure, 0, len(resp.Errors))
+	for _, deleteErr := range resp.Errors {
+		failures = append(failures, DeleteFailure{
+			Key:     aws.ToString(deleteErr.Key),
+			Code:    aws.ToString(deleteErr.Code),
+			Message: aws.ToString(deleteErr.Message),
+		})
+	}
+
+	return failures, nil
+}
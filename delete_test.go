@@ -0,0 +1,127 @@
+package s3
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelete(t *testing.T) {
+	t.Run("batches", func(t *testing.T) {
+		driver, fake := newFakeDriver(t)
+		var keys []string
+		for i := 0; i < 2500; i++ {
+			key := fmt.Sprintf("Delete/%04d.txt", i)
+			fake.put(key, []byte("Goravel"))
+			keys = append(keys, key)
+		}
+		fake.put("Delete/keep.txt", []byte("Goravel"))
+
+		assert.Nil(t, driver.Delete(keys...))
+		assert.Equal(t, 3, fake.count("DeleteObjects"))
+		assert.Equal(t, []string{"Delete/keep.txt"}, fake.keys())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		driver, fake := newFakeDriver(t)
+
+		assert.Nil(t, driver.Delete())
+		assert.Equal(t, 0, fake.count("DeleteObjects"))
+	})
+
+	t.Run("key failures", func(t *testing.T) {
+		driver, fake := newFakeDriver(t)
+		fake.put("Delete/1.txt", []byte("Goravel"))
+		fake.put("Delete/2.txt", []byte("Goravel"))
+		fake.put("Delete/3.txt", []byte("Goravel"))
+		fake.denied["Delete/3.txt"] = true
+		fake.denied["Delete/1.txt"] = true
+
+		err := driver.Delete("Delete/1.txt", "Delete/2.txt", "Delete/3.txt")
+		var deleteErr *DeleteError
+		assert.True(t, errors.As(err, &deleteErr))
+		assert.Equal(t, []DeleteFailure{
+			{Key: "Delete/1.txt", Code: "AccessDenied", Message: "Access Denied"},
+			{Key: "Delete/3.txt", Code: "AccessDenied", Message: "Access Denied"},
+		}, deleteErr.Failures)
+		assert.Equal(t, "failed to delete 2 keys: Delete/1.txt (AccessDenied: Access Denied), Delete/3.txt (AccessDenied: Access Denied)", err.Error())
+		assert.Equal(t, []string{"Delete/1.txt", "Delete/3.txt"}, fake.keys())
+	})
+
+	t.Run("request failures", func(t *testing.T) {
+		driver, fake := newFakeDriver(t)
+		var keys []string
+		for i := 0; i < 1500; i++ {
+			key := fmt.Sprintf("Delete/%04d.txt", i)
+			fake.put(key, []byte("Goravel"))
+			keys = append(keys, key)
+		}
+		fake.fail("DeleteObjects", 1, http.StatusForbidden, "AccessDenied")
+
+		err := driver.Delete(keys...)
+		var deleteErr *DeleteError
+		assert.True(t, errors.As(err, &deleteErr))
+		assert.True(t, len(deleteErr.Failures) == 1000 || len(deleteErr.Failures) == 500)
+		assert.Equal(t, "AccessDenied", deleteErr.Failures[0].Code)
+		assert.ErrorContains(t, err, "and ")
+		var apiErr smithy.APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, "AccessDenied", apiErr.ErrorCode())
+		assert.Len(t, fake.keys(), len(deleteErr.Failures))
+	})
+}
+
+func TestDeleteDirectory(t *testing.T) {
+	t.Run("siblings survive", func(t *testing.T) {
+		driver, fake := newFakeDriver(t)
+		fake.put("DeleteDirectory/", nil)
+		for i := 0; i < 2500; i++ {
+			fake.put(fmt.Sprintf("DeleteDirectory/%04d/1.txt", i), []byte("Goravel"))
+		}
+		siblings := []string{"DeleteDirectory.txt", "DeleteDirectory1/1.txt", "DeleteDirectoryA/1.txt", "Z/1.txt"}
+		for _, sibling := range siblings {
+			fake.put(sibling, []byte("Goravel"))
+		}
+
+		assert.Nil(t, driver.DeleteDirectory("DeleteDirectory"))
+		assert.Equal(t, siblings, fake.keys())
+		assert.Equal(t, 3, fake.count("DeleteObjects"))
+		assert.Equal(t, 0, fake.count("DeleteObject"))
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		driver, fake := newFakeDriver(t)
+
+		assert.Nil(t, driver.DeleteDirectory("DeleteDirectory/"))
+		assert.Equal(t, 0, fake.count("DeleteObjects"))
+	})
+
+	t.Run("key failures", func(t *testing.T) {
+		driver, fake := newFakeDriver(t)
+		for i := 0; i < 1500; i++ {
+			fake.put(fmt.Sprintf("DeleteDirectory/%04d.txt", i), []byte("Goravel"))
+		}
+		fake.denied["DeleteDirectory/1200.txt"] = true
+
+		err := driver.DeleteDirectory("DeleteDirectory")
+		var deleteErr *DeleteError
+		assert.True(t, errors.As(err, &deleteErr))
+		assert.Equal(t, []DeleteFailure{{Key: "DeleteDirectory/1200.txt", Code: "AccessDenied", Message: "Access Denied"}}, deleteErr.Failures)
+		assert.Equal(t, []string{"DeleteDirectory/1200.txt"}, fake.keys())
+	})
+
+	t.Run("list failure", func(t *testing.T) {
+		driver, fake := newFakeDriver(t)
+		fake.put("DeleteDirectory/1.txt", []byte("Goravel"))
+		fake.fail("ListObjectsV2", 1, http.StatusForbidden, "AccessDenied")
+
+		var apiErr smithy.APIError
+		assert.True(t, errors.As(driver.DeleteDirectory("DeleteDirectory"), &apiErr))
+		assert.Equal(t, "AccessDenied", apiErr.ErrorCode())
+		assert.Equal(t, []string{"DeleteDirectory/1.txt"}, fake.keys())
+	})
+}
@@ -0,0 +1,196 @@
+package s3
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// md5ETag matches the ETags that are the MD5 digest of the object, objects uploaded through a multipart
+// upload have a "-<parts>" suffix.
+var md5ETag = regexp.MustCompile(`^"?[0-9a-f]{32}"?$`)
+
+// DownloadOptions controls how DownloadTo splits the download, the zero values fall back to the
+// multipart configuration of the disk.
+type DownloadOptions struct {
+	PartSize    int64
+	Concurrency int
+}
+
+// DownloadTo downloads the file to the local path through concurrent ranged requests. The local file is
+// only replaced once the download is complete and its size, and its ETag when it's an MD5 digest, match.
+func (r *S3) DownloadTo(file, localPath string, options DownloadOptions) error {
+	if options.PartSize <= 0 {
+		options.PartSize = r.multipart.partSize
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = r.multipart.concurrency
+	}
+
+	resp, err := r.headObject(file)
+	if err != nil {
+		return err
+	}
+	size := aws.ToInt64(resp.ContentLength)
+	etag := aws.ToString(resp.ETag)
+
+	if err := os.MkdirAll(filepath.Dir(localPath), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(localPath), filepath.Base(localPath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	err = r.downloadParts(file, etag, f, size, options)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	// The ETag of an object encrypted with SSE-KMS or SSE-C isn't the MD5 digest of its content.
+	if md5ETag.MatchString(etag) && !strings.HasPrefix(string(resp.ServerSideEncryption), "aws:kms") && resp.SSECustomerAlgorithm == nil {
+		if err := verifyMD5(f.Name(), etag); err != nil {
+			return err
+		}
+	}
+
+	return os.Rename(f.Name(), localPath)
+}
+
+// downloadParts writes the parts of the object at their offset in the file, with at most
+// options.Concurrency requests in flight.
+func (r *S3) downloadParts(file, etag string, f *os.File, size int64, options DownloadOptions) error {
+	if err := f.Truncate(size); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(r.ctx)
+	defer cancel()
+
+	var (
+		errs       []error
+		downloaded atomic.Int64
+		lock       sync.Mutex
+		wg         sync.WaitGroup
+	)
+	semaphore := make(chan struct{}, options.Concurrency)
+	for offset := int64(0); offset < size; offset += options.PartSize {
+		semaphore <- struct{}{}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(offset, length int64) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			if err := r.downloadPart(ctx, file, etag, io.NewOffsetWriter(f, offset), offset, length); err != nil {
+				lock.Lock()
+				errs = append(errs, err)
+				lock.Unlock()
+				cancel()
+				return
+			}
+
+			downloaded.Add(length)
+		}(offset, min(options.PartSize, size-offset))
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if downloaded.Load() != size {
+		return fmt.Errorf("downloaded size %d doesn't match the object size %d", downloaded.Load(), size)
+	}
+
+	return nil
+}
+
+// downloadPart downloads a range of the object, retrying it up to multipart.max_retries times with a
+// jittered backoff. The object must keep the given ETag, so the parts all come from the same object.
+func (r *S3) downloadPart(ctx context.Context, file, etag string, writer *io.OffsetWriter, offset, length int64) error {
+	var err error
+	for attempt := 0; attempt <= r.multipart.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(backoff(attempt)):
+			}
+
+			if _, err := writer.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+		}
+
+		var resp *s3.GetObjectOutput
+		resp, err = r.instance.GetObject(ctx, &s3.GetObjectInput{
+			Bucket:  aws.String(r.bucket),
+			Key:     aws.String(file),
+			Range:   aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)),
+			IfMatch: aws.String(etag),
+		})
+		if err != nil {
+			err = r.wrapError(file, err)
+			continue
+		}
+
+		var n int64
+		n, err = io.Copy(writer, resp.Body)
+		if closeErr := resp.Body.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil && n != length {
+			err = io.ErrUnexpectedEOF
+		}
+		if err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+func verifyMD5(path, etag string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	hash := md5.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return err
+	}
+
+	if sum := hex.EncodeToString(hash.Sum(nil)); sum != strings.Trim(etag, `"`) {
+		return fmt.Errorf("downloaded content MD5 %s doesn't match the object ETag %s", sum, etag)
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package s3
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadTo(t *testing.T) {
+	content := make([]byte, 12*1024*1024+7)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+
+	t.Run("success", func(t *testing.T) {
+		driver, fake := newFakeDriver(t)
+		fake.put("DownloadTo/1.bin", content)
+		localPath := filepath.Join(t.TempDir(), "a", "1.bin")
+
+		assert.Nil(t, driver.DownloadTo("DownloadTo/1.bin", localPath, DownloadOptions{PartSize: 5 * 1024 * 1024, Concurrency: 2}))
+		data, err := os.ReadFile(localPath)
+		assert.Nil(t, err)
+		assert.Equal(t, content, data)
+		assert.Equal(t, 3, fake.count("GetObject"))
+		entries, err := os.ReadDir(filepath.Dir(localPath))
+		assert.Nil(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		driver, fake := newFakeDriver(t)
+		fake.put("DownloadTo/1.bin", content)
+		fake.fail("GetObject", 1, http.StatusBadRequest, "BadRequest")
+		localPath := filepath.Join(t.TempDir(), "1.bin")
+
+		assert.Nil(t, driver.DownloadTo("DownloadTo/1.bin", localPath, DownloadOptions{}))
+		data, err := os.ReadFile(localPath)
+		assert.Nil(t, err)
+		assert.Equal(t, content, data)
+		assert.Equal(t, 3, fake.count("GetObject"))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		driver, fake := newFakeDriver(t)
+		fake.put("DownloadTo/1.bin", nil)
+		localPath := filepath.Join(t.TempDir(), "1.bin")
+
+		assert.Nil(t, driver.DownloadTo("DownloadTo/1.bin", localPath, DownloadOptions{}))
+		data, err := os.ReadFile(localPath)
+		assert.Nil(t, err)
+		assert.Empty(t, data)
+	})
+
+	t.Run("etag mismatch", func(t *testing.T) {
+		driver, fake := newFakeDriver(t)
+		fake.put("DownloadTo/1.bin", content)
+		fake.objects["DownloadTo/1.bin"].etag = `"00000000000000000000000000000000"`
+		localPath := filepath.Join(t.TempDir(), "1.bin")
+		assert.Nil(t, os.WriteFile(localPath, []byte("Goravel"), 0644))
+
+		assert.ErrorContains(t, driver.DownloadTo("DownloadTo/1.bin", localPath, DownloadOptions{}), "doesn't match the object ETag")
+		data, err := os.ReadFile(localPath)
+		assert.Nil(t, err)
+		assert.Equal(t, "Goravel", string(data))
+		entries, err := os.ReadDir(filepath.Dir(localPath))
+		assert.Nil(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		driver, _ := newFakeDriver(t)
+		localPath := filepath.Join(t.TempDir(), "1.bin")
+
+		assert.NotNil(t, driver.DownloadTo("DownloadTo/1.bin", localPath, DownloadOptions{}))
+		assert.NoFileExists(t, localPath)
+	})
+}
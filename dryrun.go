@@ -0,0 +1,60 @@
+package s3
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// DryRun plans the destructive operations of a disk, its methods return the keys the operations would
+// touch without mutating anything.
+type DryRun struct {
+	driver *S3
+}
+
+// DryRun returns the dry-run mode of the disk.
+func (r *S3) DryRun() *DryRun {
+	return &DryRun{driver: r}
+}
+
+// Delete returns the keys Delete would delete, in the order Delete sends them.
+func (r *DryRun) Delete(files ...string) ([]string, error) {
+	return slices.Clone(files), nil
+}
+
+// DeleteDirectory returns the keys DeleteDirectory would delete.
+func (r *DryRun) DeleteDirectory(directory string) ([]string, error) {
+	if !strings.HasSuffix(directory, "/") {
+		directory += "/"
+	}
+
+	var keys []string
+	paginator := s3.NewListObjectsV2Paginator(r.driver.instance, &s3.ListObjectsV2Input{
+		Bucket: aws.String(r.driver.bucket),
+		Prefix: aws.String(directory),
+	})
+	for paginator.HasMorePages() {
+		listObjectsV2Response, err := paginator.NextPage(r.driver.ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range listObjectsV2Response.Contents {
+			keys = append(keys, aws.ToString(item.Key))
+		}
+	}
+
+	return keys, nil
+}
+
+// Move returns the keys Move would touch, the old file is deleted and the new file is written.
+// An error is returned if the old file can't be read.
+func (r *DryRun) Move(oldFile, newFile string) ([]string, error) {
+	if _, err := r.driver.headObject(oldFile); err != nil {
+		return nil, err
+	}
+
+	return []string{oldFile, newFile}, nil
+}
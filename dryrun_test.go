@@ -0,0 +1,91 @@
+package s3
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDryRun(t *testing.T) {
+	driver, fake := newFakeDriver(t)
+	fake.put("DryRun/", nil)
+	for i := 0; i < 1500; i++ {
+		fake.put(fmt.Sprintf("DryRun/%04d.txt", i), []byte("Goravel"))
+	}
+	fake.put("DryRun1/1.txt", []byte("Goravel"))
+	keys := fake.keys()
+
+	deleted, err := driver.DryRun().Delete("DryRun/0001.txt", "DryRun/0002.txt", "DryRun/0001.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"DryRun/0001.txt", "DryRun/0002.txt", "DryRun/0001.txt"}, deleted)
+
+	deleted, err = driver.DryRun().DeleteDirectory("DryRun")
+	assert.Nil(t, err)
+	assert.Len(t, deleted, 1501)
+	assert.Equal(t, "DryRun/", deleted[0])
+	assert.Equal(t, "DryRun/1499.txt", deleted[len(deleted)-1])
+
+	touched, err := driver.DryRun().Move("DryRun1/1.txt", "DryRun2/1.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"DryRun1/1.txt", "DryRun2/1.txt"}, touched)
+
+	_, err = driver.DryRun().Move("DryRun1/2.txt", "DryRun2/2.txt")
+	assert.NotNil(t, err)
+
+	assert.Equal(t, keys, fake.keys())
+	assert.Equal(t, 0, fake.count("DeleteObjects"))
+	assert.Equal(t, 0, fake.count("CopyObject"))
+}
+
+func TestDeleteGuard(t *testing.T) {
+	errTooMany := errors.New("too many keys")
+	var guarded [][]string
+	guard := func(keys []string) error {
+		guarded = append(guarded, keys)
+		if len(keys) > 2 {
+			return errTooMany
+		}
+
+		return nil
+	}
+
+	driver, fake := newFakeDriver(t, map[string]any{
+		"filesystems.disks.s3.delete_guard.threshold": 1,
+		"filesystems.disks.s3.delete_guard.callback":  guard,
+	})
+	for _, key := range []string{"Guard/1.txt", "Guard/2.txt", "Guard/3.txt", "Guard1/1.txt", "Guard1/2.txt", "Move/1.txt"} {
+		fake.put(key, []byte("Goravel"))
+	}
+
+	// The deletes below the threshold don't call the guard.
+	assert.Nil(t, driver.Move("Move/1.txt", "Move1/1.txt"))
+	assert.Empty(t, guarded)
+
+	err := driver.DeleteDirectory("Guard")
+	assert.ErrorIs(t, err, ErrDeleteVetoed)
+	assert.ErrorIs(t, err, errTooMany)
+	assert.Equal(t, [][]string{{"Guard/1.txt", "Guard/2.txt", "Guard/3.txt"}}, guarded)
+
+	assert.ErrorIs(t, driver.Delete("Guard/1.txt", "Guard/2.txt", "Guard/3.txt"), ErrDeleteVetoed)
+	assert.Equal(t, 1, fake.count("DeleteObjects"))
+
+	assert.Nil(t, driver.DeleteDirectory("Guard1"))
+	assert.Nil(t, driver.Delete("Guard/1.txt", "Guard/2.txt"))
+	assert.Len(t, guarded, 4)
+	assert.Equal(t, []string{"Guard/3.txt", "Move1/1.txt"}, fake.keys())
+}
+
+func TestDeleteGuard_Move(t *testing.T) {
+	var guard DeleteGuard = func(keys []string) error {
+		return errors.New("read only")
+	}
+	driver, fake := newFakeDriver(t, map[string]any{
+		"filesystems.disks.s3.delete_guard.callback": guard,
+	})
+	fake.put("Move/1.txt", []byte("Goravel"))
+
+	assert.ErrorIs(t, driver.Move("Move/1.txt", "Move1/1.txt"), ErrDeleteVetoed)
+	assert.Equal(t, []string{"Move/1.txt"}, fake.keys())
+}
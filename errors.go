@@ -0,0 +1,88 @@
+package s3
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+)
+
+var (
+	ErrObjectNotFound     = errors.New("object not found")
+	ErrBucketNotFound     = errors.New("bucket not found")
+	ErrAccessDenied       = errors.New("access denied")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// Error wraps the error of an operation on a key of a disk, it matches both the original error and,
+// when the error is recognized, one of the sentinel errors with errors.Is.
+type Error struct {
+	Disk string
+	Key  string
+	Err  error
+
+	sentinel error
+}
+
+func (r *Error) Error() string {
+	return fmt.Sprintf("s3 disk %s, key %s: %v", r.Disk, r.Key, r.Err)
+}
+
+func (r *Error) Unwrap() []error {
+	if r.sentinel == nil {
+		return []error{r.Err}
+	}
+
+	return []error{r.sentinel, r.Err}
+}
+
+// wrapError wraps the error with the disk and the key, nil is returned if the error is nil.
+func (r *S3) wrapError(key string, err error) error {
+	var s3Err *Error
+	if err == nil || errors.As(err, &s3Err) {
+		return err
+	}
+
+	return &Error{
+		Disk:     r.disk,
+		Key:      key,
+		Err:      err,
+		sentinel: sentinelError(err),
+	}
+}
+
+// sentinelError maps the error code, or the HTTP status for the responses without body, to a sentinel error.
+func sentinelError(err error) error {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "NoSuchKey", "NoSuchVersion":
+			return ErrObjectNotFound
+		case "NoSuchBucket":
+			return ErrBucketNotFound
+		case "AccessDenied", "AllAccessDisabled", "AccountProblem":
+			return ErrAccessDenied
+		case "InvalidAccessKeyId", "SignatureDoesNotMatch", "ExpiredToken", "InvalidToken", "TokenRefreshRequired":
+			return ErrInvalidCredentials
+		case "PreconditionFailed":
+			return ErrPreconditionFailed
+		}
+	}
+
+	var responseErr *awshttp.ResponseError
+	if errors.As(err, &responseErr) {
+		switch responseErr.HTTPStatusCode() {
+		case http.StatusNotFound:
+			return ErrObjectNotFound
+		case http.StatusForbidden:
+			return ErrAccessDenied
+		case http.StatusPreconditionFailed:
+			return ErrPreconditionFailed
+		}
+	}
+
+	return nil
+}
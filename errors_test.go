@@ -0,0 +1,75 @@
+package s3
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrors(t *testing.T) {
+	driver, fake := newFakeDriver(t)
+
+	_, err := driver.GetBytes("Errors/1.txt")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+	var noSuchKey *types.NoSuchKey
+	assert.True(t, errors.As(err, &noSuchKey))
+	var s3Err *Error
+	assert.True(t, errors.As(err, &s3Err))
+	assert.Equal(t, "s3", s3Err.Disk)
+	assert.Equal(t, "Errors/1.txt", s3Err.Key)
+	assert.Contains(t, err.Error(), "s3 disk s3, key Errors/1.txt: ")
+
+	_, err = driver.Size("Errors/1.txt")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+	_, err = driver.MimeType("Errors/1.txt")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+	_, err = driver.LastModified("Errors/1.txt")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+	assert.ErrorIs(t, driver.Copy("Errors/1.txt", "Errors/2.txt"), ErrObjectNotFound)
+
+	fake.put("Errors/1.txt", []byte("Goravel"))
+	tests := []struct {
+		code     string
+		status   int
+		sentinel error
+	}{
+		{code: "AccessDenied", status: http.StatusForbidden, sentinel: ErrAccessDenied},
+		{code: "NoSuchBucket", status: http.StatusNotFound, sentinel: ErrBucketNotFound},
+		{code: "InvalidAccessKeyId", status: http.StatusForbidden, sentinel: ErrInvalidCredentials},
+		{code: "ExpiredToken", status: http.StatusBadRequest, sentinel: ErrInvalidCredentials},
+	}
+	for _, test := range tests {
+		t.Run(test.code, func(t *testing.T) {
+			fake.fail("GetObject", 1, test.status, test.code)
+			_, err := driver.Get("Errors/1.txt")
+			assert.ErrorIs(t, err, test.sentinel)
+		})
+	}
+
+	t.Run("without body", func(t *testing.T) {
+		fake.fail("HeadObject", 1, http.StatusForbidden, "AccessDenied")
+		_, err := driver.Size("Errors/1.txt")
+		assert.ErrorIs(t, err, ErrAccessDenied)
+		assert.NotErrorIs(t, err, ErrObjectNotFound)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		fake.fail("GetObject", 1, http.StatusBadRequest, "InvalidArgument")
+		_, err := driver.GetBytes("Errors/1.txt")
+		assert.True(t, errors.As(err, &s3Err))
+		for _, sentinel := range []error{ErrObjectNotFound, ErrBucketNotFound, ErrAccessDenied, ErrInvalidCredentials, ErrPreconditionFailed} {
+			assert.NotErrorIs(t, err, sentinel)
+		}
+	})
+
+	t.Run("precondition", func(t *testing.T) {
+		reader, err := driver.OpenReaderAt("Errors/1.txt")
+		assert.Nil(t, err)
+		fake.put("Errors/1.txt", []byte("Goravel!"))
+		_, err = reader.ReadAt(make([]byte, 1), 0)
+		assert.ErrorIs(t, err, ErrPreconditionFailed)
+	})
+}
@@ -0,0 +1,930 @@
+package s3
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mocksconfig "github.com/goravel/framework/mocks/config"
+)
+
+// fakeS3 is a minimal in-memory S3 stand-in, it implements the subset of the REST API used by the driver.
+type fakeS3 struct {
+	// accessKey is the access key that signed the last request.
+	accessKey string
+	bucket    string
+	// denied are the keys that DeleteObjects fails to delete with AccessDenied.
+	denied map[string]bool
+	// kmsDenied makes the requests of the checksum of an aws:kms object fail with AccessDenied, like for a caller
+	// without kms:Decrypt.
+	kmsDenied bool
+	// slowDowns are the numbers of DeleteObjects requests that fail to delete the keys with SlowDown.
+	slowDowns map[string]int
+	failures  map[string]*fakeFailure
+	lock      sync.Mutex
+	objects   map[string]*fakeObject
+	requests  map[string]int
+	server    *httptest.Server
+	uploads   map[string]*fakeUpload
+}
+
+type fakeUpload struct {
+	acl         string
+	contentType string
+	headers     http.Header
+	key         string
+	parts       map[int][]byte
+	tags        url.Values
+}
+
+type fakeFailure struct {
+	code   string
+	status int
+	times  int
+}
+
+type fakeObject struct {
+	// acl is the canned ACL of the object.
+	acl         string
+	contentType string
+	data        []byte
+	etag        string
+	// headers are the stored headers returned by GetObject and HeadObject, see fakeHeaders.
+	headers      http.Header
+	lastModified time.Time
+	// tags are the tags of the object, from the x-amz-tagging header or PutObjectTagging.
+	tags url.Values
+}
+
+// fakeHeaders returns the headers of a request stored with an object.
+func fakeHeaders(req *http.Request) http.Header {
+	headers := make(http.Header)
+	for name, values := range req.Header {
+		switch name = http.CanonicalHeaderKey(name); {
+		case name == "Cache-Control", name == "Content-Disposition", name == "Expires", name == "X-Amz-Storage-Class",
+			strings.HasPrefix(name, "X-Amz-Meta-"):
+			headers[name] = values
+		case name == "Content-Encoding":
+			// The aws-chunked encoding of streaming signatures isn't stored.
+			var encodings []string
+			for _, encoding := range strings.Split(strings.Join(values, ","), ",") {
+				if encoding = strings.TrimSpace(encoding); encoding != "" && encoding != "aws-chunked" {
+					encodings = append(encodings, encoding)
+				}
+			}
+			if len(encodings) > 0 {
+				headers.Set(name, strings.Join(encodings, ","))
+			}
+		}
+	}
+
+	return headers
+}
+
+func newFakeS3(t testing.TB) *fakeS3 {
+	fake := &fakeS3{
+		bucket:    "goravel",
+		denied:    make(map[string]bool),
+		slowDowns: make(map[string]int),
+		failures:  make(map[string]*fakeFailure),
+		objects:   make(map[string]*fakeObject),
+		requests:  make(map[string]int),
+		uploads:   make(map[string]*fakeUpload),
+	}
+	fake.server = httptest.NewServer(http.HandlerFunc(fake.handle))
+	t.Cleanup(fake.server.Close)
+
+	return fake
+}
+
+// newFakeDriver creates a driver connected to a fake S3 server, values override the default disk configuration.
+func newFakeDriver(t testing.TB, values ...map[string]any) (*S3, *fakeS3) {
+	fake := newFakeS3(t)
+	configuration := map[string]any{
+		"app.timezone":                        "UTC",
+		"filesystems.disks.s3.key":            "key",
+		"filesystems.disks.s3.secret":         "secret",
+		"filesystems.disks.s3.region":         "us-east-1",
+		"filesystems.disks.s3.bucket":         fake.bucket,
+		"filesystems.disks.s3.url":            "https://goravel.dev",
+		"filesystems.disks.s3.endpoint":       fake.server.URL,
+		"filesystems.disks.s3.use_path_style": true,
+		// The retries don't slow the tests down.
+		"filesystems.disks.s3.retry.max_backoff": 10 * time.Millisecond,
+	}
+	for _, value := range values {
+		for key, item := range value {
+			configuration[key] = item
+		}
+	}
+
+	driver, err := NewS3(context.Background(), newMockConfig(t, configuration), "s3")
+	assert.Nil(t, err)
+
+	return driver, fake
+}
+
+// newMockConfig returns a config mock that resolves every getter from the given values.
+func newMockConfig(t testing.TB, values map[string]any) *mocksconfig.Config {
+	mockConfig := mocksconfig.NewConfig(t)
+	get := func(path string, defaultValue ...any) any {
+		if value, ok := values[path]; ok {
+			return value
+		}
+		if len(defaultValue) > 0 {
+			return defaultValue[0]
+		}
+
+		return nil
+	}
+	mockConfig.EXPECT().Get(mock.Anything).RunAndReturn(get).Maybe()
+	mockConfig.EXPECT().Get(mock.Anything, mock.Anything).RunAndReturn(get).Maybe()
+	mockConfig.EXPECT().GetString(mock.Anything).RunAndReturn(func(path string, defaultValue ...string) string {
+		return configValue(values, path, defaultValue)
+	}).Maybe()
+	mockConfig.EXPECT().GetString(mock.Anything, mock.Anything).RunAndReturn(func(path string, defaultValue ...string) string {
+		return configValue(values, path, defaultValue)
+	}).Maybe()
+	mockConfig.EXPECT().GetBool(mock.Anything, mock.Anything).RunAndReturn(func(path string, defaultValue ...bool) bool {
+		return configValue(values, path, defaultValue)
+	}).Maybe()
+	mockConfig.EXPECT().GetDuration(mock.Anything).RunAndReturn(func(path string, defaultValue ...time.Duration) time.Duration {
+		return configValue(values, path, defaultValue)
+	}).Maybe()
+	mockConfig.EXPECT().GetInt(mock.Anything).RunAndReturn(func(path string, defaultValue ...int) int {
+		return configValue(values, path, defaultValue)
+	}).Maybe()
+	mockConfig.EXPECT().GetInt(mock.Anything, mock.Anything).RunAndReturn(func(path string, defaultValue ...int) int {
+		return configValue(values, path, defaultValue)
+	}).Maybe()
+
+	return mockConfig
+}
+
+func configValue[T any](values map[string]any, path string, defaultValue []T) T {
+	if value, ok := values[path].(T); ok {
+		return value
+	}
+	if len(defaultValue) > 0 {
+		return defaultValue[0]
+	}
+
+	var zero T
+
+	return zero
+}
+
+func (r *fakeS3) count(operation string) int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.requests[operation]
+}
+
+func (r *fakeS3) keys() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var keys []string
+	for key := range r.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (r *fakeS3) put(key string, data []byte) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.store(key, data, "binary/octet-stream")
+}
+
+func (r *fakeS3) store(key string, data []byte, contentType string) *fakeObject {
+	sum := md5.Sum(data)
+	object := &fakeObject{
+		contentType:  contentType,
+		data:         data,
+		etag:         `"` + hex.EncodeToString(sum[:]) + `"`,
+		lastModified: time.Now().UTC().Truncate(time.Second),
+	}
+	r.objects[key] = object
+
+	return object
+}
+
+func (r *fakeS3) handle(w http.ResponseWriter, req *http.Request) {
+	path := strings.TrimPrefix(req.URL.Path, "/"+r.bucket)
+	key := strings.TrimPrefix(path, "/")
+	query := req.URL.Query()
+	operation := fakeOperation(req, key, query)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.requests[operation]++
+	if _, credential, ok := strings.Cut(req.Header.Get("Authorization"), "Credential="); ok {
+		r.accessKey, _, _ = strings.Cut(credential, "/")
+	}
+	if failure, ok := r.failures[operation]; ok && failure.times != 0 {
+		failure.times--
+		writeFakeError(w, failure.status, failure.code, "Injected failure")
+		return
+	}
+
+	switch operation {
+	case "ListObjectsV2":
+		r.listObjectsV2(w, query)
+	case "DeleteObjects":
+		r.deleteObjects(w, req)
+	case "CreateMultipartUpload":
+		r.createMultipartUpload(w, req, key)
+	case "UploadPart":
+		r.uploadPart(w, req, query)
+	case "CompleteMultipartUpload":
+		r.completeMultipartUpload(w, req, key, query)
+	case "ListParts":
+		r.listParts(w, query)
+	case "AbortMultipartUpload":
+		delete(r.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case "PutObjectAcl":
+		r.putObjectAcl(w, req, key)
+	case "GetObjectAcl":
+		r.getObjectAcl(w, key)
+	case "PutObjectTagging":
+		r.putObjectTagging(w, req, key)
+	case "GetObjectTagging":
+		r.getObjectTagging(w, key)
+	case "DeleteObjectTagging":
+		r.deleteObjectTagging(w, key)
+	case "CopyObject":
+		r.copyObject(w, req, key)
+	case "PutObject":
+		r.putObject(w, req, key)
+	case "GetObject":
+		r.getObject(w, req, key, true)
+	case "HeadObject":
+		r.getObject(w, req, key, false)
+	case "DeleteObject":
+		delete(r.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeFakeError(w, http.StatusNotImplemented, "NotImplemented", req.Method+" "+req.URL.String())
+	}
+}
+
+// fail makes the next given number of requests of the operation fail, -1 makes all of them fail.
+func (r *fakeS3) fail(operation string, times, status int, code string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.failures[operation] = &fakeFailure{code: code, status: status, times: times}
+}
+
+func fakeOperation(req *http.Request, key string, query url.Values) string {
+	switch {
+	case req.Method == http.MethodGet && key == "" && query.Get("list-type") == "2":
+		return "ListObjectsV2"
+	case req.Method == http.MethodPost && key == "" && query.Has("delete"):
+		return "DeleteObjects"
+	case req.Method == http.MethodPut && query.Has("acl"):
+		return "PutObjectAcl"
+	case req.Method == http.MethodGet && query.Has("acl"):
+		return "GetObjectAcl"
+	case req.Method == http.MethodPut && query.Has("tagging"):
+		return "PutObjectTagging"
+	case req.Method == http.MethodGet && query.Has("tagging"):
+		return "GetObjectTagging"
+	case req.Method == http.MethodDelete && query.Has("tagging"):
+		return "DeleteObjectTagging"
+	case req.Method == http.MethodPost && query.Has("uploads"):
+		return "CreateMultipartUpload"
+	case req.Method == http.MethodPut && query.Has("uploadId"):
+		return "UploadPart"
+	case req.Method == http.MethodPost && query.Has("uploadId"):
+		return "CompleteMultipartUpload"
+	case req.Method == http.MethodDelete && query.Has("uploadId"):
+		return "AbortMultipartUpload"
+	case req.Method == http.MethodGet && query.Has("uploadId"):
+		return "ListParts"
+	case req.Method == http.MethodPut && req.Header.Get("X-Amz-Copy-Source") != "":
+		return "CopyObject"
+	case req.Method == http.MethodPut:
+		return "PutObject"
+	case req.Method == http.MethodGet:
+		return "GetObject"
+	case req.Method == http.MethodHead:
+		return "HeadObject"
+	case req.Method == http.MethodDelete:
+		return "DeleteObject"
+	default:
+		return req.Method
+	}
+}
+
+type fakeListBucketResult struct {
+	XMLName               xml.Name           `xml:"ListBucketResult"`
+	Name                  string             `xml:"Name"`
+	Prefix                string             `xml:"Prefix"`
+	Delimiter             string             `xml:"Delimiter,omitempty"`
+	MaxKeys               int                `xml:"MaxKeys"`
+	KeyCount              int                `xml:"KeyCount"`
+	IsTruncated           bool               `xml:"IsTruncated"`
+	ContinuationToken     string             `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string             `xml:"NextContinuationToken,omitempty"`
+	StartAfter            string             `xml:"StartAfter,omitempty"`
+	Contents              []fakeListObject   `xml:"Contents"`
+	CommonPrefixes        []fakeCommonPrefix `xml:"CommonPrefixes"`
+}
+
+type fakeListObject struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int    `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+type fakeCommonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+func (r *fakeS3) listObjectsV2(w http.ResponseWriter, query url.Values) {
+	prefix := query.Get("prefix")
+	delimiter := query.Get("delimiter")
+	maxKeys := 1000
+	if value, err := strconv.Atoi(query.Get("max-keys")); err == nil && value < maxKeys {
+		maxKeys = value
+	}
+	marker := query.Get("start-after")
+	if token := query.Get("continuation-token"); token != "" {
+		marker = token
+	}
+
+	var keys []string
+	for key := range r.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	result := fakeListBucketResult{
+		Name:              r.bucket,
+		Prefix:            prefix,
+		Delimiter:         delimiter,
+		MaxKeys:           maxKeys,
+		ContinuationToken: query.Get("continuation-token"),
+		StartAfter:        query.Get("start-after"),
+	}
+	for _, key := range keys {
+		// Keys rolled up into the common prefix returned last are skipped as well.
+		if key <= marker || (delimiter != "" && len(marker) > len(prefix) && strings.HasSuffix(marker, delimiter) && strings.HasPrefix(key, marker)) {
+			continue
+		}
+		if result.KeyCount == maxKeys {
+			result.IsTruncated = true
+			break
+		}
+
+		if delimiter != "" {
+			if index := strings.Index(key[len(prefix):], delimiter); index >= 0 {
+				commonPrefix := key[:len(prefix)+index+len(delimiter)]
+				result.CommonPrefixes = append(result.CommonPrefixes, fakeCommonPrefix{Prefix: commonPrefix})
+				result.KeyCount++
+				marker = commonPrefix
+				continue
+			}
+		}
+
+		object := r.objects[key]
+		result.Contents = append(result.Contents, fakeListObject{
+			Key:          key,
+			LastModified: object.lastModified.Format(time.RFC3339),
+			ETag:         object.etag,
+			Size:         len(object.data),
+			StorageClass: "STANDARD",
+		})
+		result.KeyCount++
+		marker = key
+	}
+	if result.IsTruncated {
+		result.NextContinuationToken = marker
+	}
+
+	writeFakeXML(w, result)
+}
+
+func (r *fakeS3) putObject(w http.ResponseWriter, req *http.Request, key string) {
+	data, err := readFakeBody(req)
+	if err != nil {
+		writeFakeError(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+		return
+	}
+
+	object := r.store(key, data, req.Header.Get("Content-Type"))
+	object.acl = fakeACL(req)
+	object.headers = fakeHeaders(req)
+	object.tags = fakeTags(req)
+	w.Header().Set("ETag", object.etag)
+	w.WriteHeader(http.StatusOK)
+}
+
+// fakeACL returns the canned ACL of a request, S3 applies private by default.
+func fakeACL(req *http.Request) string {
+	if acl := req.Header.Get("X-Amz-Acl"); acl != "" {
+		return acl
+	}
+
+	return "private"
+}
+
+func (r *fakeS3) putObjectAcl(w http.ResponseWriter, req *http.Request, key string) {
+	object, ok := r.objects[key]
+	if !ok {
+		writeFakeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+
+	object.acl = fakeACL(req)
+	w.WriteHeader(http.StatusOK)
+}
+
+// getObjectAcl returns the grants of the canned ACL of the object.
+func (r *fakeS3) getObjectAcl(w http.ResponseWriter, key string) {
+	object, ok := r.objects[key]
+	if !ok {
+		writeFakeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+
+	type grant struct {
+		Grantee struct {
+			Type string `xml:"xsi:type,attr"`
+			ID   string `xml:"ID,omitempty"`
+			URI  string `xml:"URI,omitempty"`
+		} `xml:"Grantee"`
+		Permission string `xml:"Permission"`
+	}
+	owner := grant{Permission: "FULL_CONTROL"}
+	owner.Grantee.Type, owner.Grantee.ID = "CanonicalUser", "goravel"
+	grants := []grant{owner}
+	group := func(uri, permission string) {
+		var groupGrant grant
+		groupGrant.Grantee.Type, groupGrant.Grantee.URI, groupGrant.Permission = "Group", uri, permission
+		grants = append(grants, groupGrant)
+	}
+	switch object.acl {
+	case "public-read":
+		group("http://acs.amazonaws.com/groups/global/AllUsers", "READ")
+	case "public-read-write":
+		group("http://acs.amazonaws.com/groups/global/AllUsers", "READ")
+		group("http://acs.amazonaws.com/groups/global/AllUsers", "WRITE")
+	case "authenticated-read":
+		group("http://acs.amazonaws.com/groups/global/AuthenticatedUsers", "READ")
+	}
+
+	writeFakeXML(w, struct {
+		XMLName xml.Name `xml:"AccessControlPolicy"`
+		XSI     string   `xml:"xmlns:xsi,attr"`
+		Owner   struct {
+			ID string `xml:"ID"`
+		} `xml:"Owner"`
+		Grants []grant `xml:"AccessControlList>Grant"`
+	}{XSI: "http://www.w3.org/2001/XMLSchema-instance", Grants: grants})
+}
+
+// fakeTags returns the tags of the x-amz-tagging header of a request.
+func fakeTags(req *http.Request) url.Values {
+	tags, _ := url.ParseQuery(req.Header.Get("X-Amz-Tagging"))
+
+	return tags
+}
+
+type fakeTagging struct {
+	XMLName xml.Name  `xml:"Tagging"`
+	Tags    []fakeTag `xml:"TagSet>Tag"`
+}
+
+type fakeTag struct {
+	Key   string `xml:"Key"`
+	Value string `xml:"Value"`
+}
+
+func (r *fakeS3) putObjectTagging(w http.ResponseWriter, req *http.Request, key string) {
+	object, ok := r.objects[key]
+	if !ok {
+		writeFakeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+
+	var tagging fakeTagging
+	if err := xml.NewDecoder(req.Body).Decode(&tagging); err != nil {
+		writeFakeError(w, http.StatusBadRequest, "MalformedXML", err.Error())
+		return
+	}
+
+	object.tags = make(url.Values)
+	for _, tag := range tagging.Tags {
+		object.tags.Set(tag.Key, tag.Value)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (r *fakeS3) getObjectTagging(w http.ResponseWriter, key string) {
+	object, ok := r.objects[key]
+	if !ok {
+		writeFakeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+
+	var tagging fakeTagging
+	for key := range object.tags {
+		tagging.Tags = append(tagging.Tags, fakeTag{Key: key, Value: object.tags.Get(key)})
+	}
+	sort.Slice(tagging.Tags, func(i, j int) bool {
+		return tagging.Tags[i].Key < tagging.Tags[j].Key
+	})
+
+	writeFakeXML(w, tagging)
+}
+
+func (r *fakeS3) deleteObjectTagging(w http.ResponseWriter, key string) {
+	object, ok := r.objects[key]
+	if !ok {
+		writeFakeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+
+	object.tags = nil
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (r *fakeS3) copyObject(w http.ResponseWriter, req *http.Request, key string) {
+	// S3 decodes the copy source like a query, a plus is a space.
+	source, err := url.QueryUnescape(req.Header.Get("X-Amz-Copy-Source"))
+	if err != nil {
+		writeFakeError(w, http.StatusBadRequest, "InvalidArgument", err.Error())
+		return
+	}
+
+	origin, ok := r.objects[strings.TrimPrefix(strings.TrimPrefix(source, "/"), r.bucket+"/")]
+	if !ok {
+		writeFakeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+
+	if etag := req.Header.Get("X-Amz-Copy-Source-If-Match"); etag != "" && etag != origin.etag {
+		writeFakeError(w, http.StatusPreconditionFailed, "PreconditionFailed", "At least one of the pre-conditions you specified did not hold")
+		return
+	}
+
+	object := r.store(key, bytes.Clone(origin.data), origin.contentType)
+	object.acl = fakeACL(req)
+	object.headers = origin.headers.Clone()
+	if req.Header.Get("X-Amz-Metadata-Directive") == "REPLACE" {
+		object.contentType = req.Header.Get("Content-Type")
+		object.headers = fakeHeaders(req)
+	}
+	object.tags = origin.tags
+	if req.Header.Get("X-Amz-Tagging-Directive") == "REPLACE" {
+		object.tags = fakeTags(req)
+	}
+	writeFakeXML(w, struct {
+		XMLName      xml.Name `xml:"CopyObjectResult"`
+		ETag         string   `xml:"ETag"`
+		LastModified string   `xml:"LastModified"`
+	}{ETag: object.etag, LastModified: object.lastModified.Format(time.RFC3339)})
+}
+
+func (r *fakeS3) getObject(w http.ResponseWriter, req *http.Request, key string, withBody bool) {
+	object, ok := r.objects[key]
+	if !ok {
+		if withBody {
+			writeFakeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		return
+	}
+	if etag := req.Header.Get("If-Match"); etag != "" && etag != object.etag {
+		writeFakeError(w, http.StatusPreconditionFailed, "PreconditionFailed", "At least one of the pre-conditions you specified did not hold")
+		return
+	}
+
+	data, status := object.data, http.StatusOK
+	if byteRange := req.Header.Get("Range"); byteRange != "" {
+		var start, end int
+		bounds := strings.SplitN(strings.TrimPrefix(byteRange, "bytes="), "-", 2)
+		start, _ = strconv.Atoi(bounds[0])
+		end = len(data) - 1
+		if bounds[1] != "" {
+			end, _ = strconv.Atoi(bounds[1])
+			end = min(end, len(data)-1)
+		}
+		if start >= len(data) || start > end {
+			writeFakeError(w, http.StatusRequestedRangeNotSatisfiable, "InvalidRange", "The requested range is not satisfiable")
+			return
+		}
+
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+		data, status = data[start:end+1], http.StatusPartialContent
+	}
+
+	checksumMode := req.Header.Get("X-Amz-Checksum-Mode") == "ENABLED"
+	if checksumMode && r.kmsDenied && object.headers.Get("X-Amz-Server-Side-Encryption") == "aws:kms" {
+		if withBody {
+			writeFakeError(w, http.StatusForbidden, "AccessDenied", "Access Denied")
+		} else {
+			w.WriteHeader(http.StatusForbidden)
+		}
+		return
+	}
+
+	for name, values := range object.headers {
+		// The checksum is only returned when it's requested.
+		if !checksumMode && strings.HasPrefix(name, "X-Amz-Checksum-") {
+			continue
+		}
+		w.Header()[name] = values
+	}
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("Content-Type", object.contentType)
+	w.Header().Set("ETag", object.etag)
+	w.Header().Set("Last-Modified", object.lastModified.Format(http.TimeFormat))
+	w.WriteHeader(status)
+	if withBody {
+		_, _ = w.Write(data)
+	}
+}
+
+func (r *fakeS3) deleteObjects(w http.ResponseWriter, req *http.Request) {
+	var input struct {
+		Objects []struct {
+			Key string `xml:"Key"`
+		} `xml:"Object"`
+		Quiet bool `xml:"Quiet"`
+	}
+	if err := xml.NewDecoder(req.Body).Decode(&input); err != nil {
+		writeFakeError(w, http.StatusBadRequest, "MalformedXML", err.Error())
+		return
+	}
+	if len(input.Objects) > 1000 {
+		writeFakeError(w, http.StatusBadRequest, "MalformedXML", "The XML you provided was not well-formed or did not validate against our published schema")
+		return
+	}
+
+	type deleted struct {
+		Key string `xml:"Key"`
+	}
+	type deleteError struct {
+		Key     string `xml:"Key"`
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	result := struct {
+		XMLName xml.Name      `xml:"DeleteResult"`
+		Deleted []deleted     `xml:"Deleted"`
+		Errors  []deleteError `xml:"Error"`
+	}{}
+	for _, object := range input.Objects {
+		if r.denied[object.Key] {
+			result.Errors = append(result.Errors, deleteError{Key: object.Key, Code: "AccessDenied", Message: "Access Denied"})
+			continue
+		}
+		if r.slowDowns[object.Key] > 0 {
+			r.slowDowns[object.Key]--
+			result.Errors = append(result.Errors, deleteError{Key: object.Key, Code: "SlowDown", Message: "Please reduce your request rate."})
+			continue
+		}
+
+		delete(r.objects, object.Key)
+		if !input.Quiet {
+			result.Deleted = append(result.Deleted, deleted{Key: object.Key})
+		}
+	}
+
+	writeFakeXML(w, result)
+}
+
+func (r *fakeS3) createMultipartUpload(w http.ResponseWriter, req *http.Request, key string) {
+	uploadId := strconv.Itoa(len(r.uploads)+1) + "-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	r.uploads[uploadId] = &fakeUpload{
+		acl:         fakeACL(req),
+		contentType: req.Header.Get("Content-Type"),
+		headers:     fakeHeaders(req),
+		key:         key,
+		parts:       make(map[int][]byte),
+		tags:        fakeTags(req),
+	}
+
+	writeFakeXML(w, struct {
+		XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+		Bucket   string   `xml:"Bucket"`
+		Key      string   `xml:"Key"`
+		UploadId string   `xml:"UploadId"`
+	}{Bucket: r.bucket, Key: key, UploadId: uploadId})
+}
+
+func (r *fakeS3) uploadPart(w http.ResponseWriter, req *http.Request, query url.Values) {
+	upload, ok := r.uploads[query.Get("uploadId")]
+	if !ok {
+		writeFakeError(w, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist.")
+		return
+	}
+
+	partNumber, err := strconv.Atoi(query.Get("partNumber"))
+	if err != nil {
+		writeFakeError(w, http.StatusBadRequest, "InvalidArgument", err.Error())
+		return
+	}
+
+	data, err := readFakeBody(req)
+	if err != nil {
+		writeFakeError(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+		return
+	}
+
+	upload.parts[partNumber] = data
+	sum := md5.Sum(data)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (r *fakeS3) listParts(w http.ResponseWriter, query url.Values) {
+	upload, ok := r.uploads[query.Get("uploadId")]
+	if !ok {
+		writeFakeError(w, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist.")
+		return
+	}
+
+	maxParts := 1000
+	if value, err := strconv.Atoi(query.Get("max-parts")); err == nil && value < maxParts {
+		maxParts = value
+	}
+	marker, _ := strconv.Atoi(query.Get("part-number-marker"))
+
+	var numbers []int
+	for number := range upload.parts {
+		if number > marker {
+			numbers = append(numbers, number)
+		}
+	}
+	sort.Ints(numbers)
+
+	type fakePart struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+		Size       int    `xml:"Size"`
+	}
+	result := struct {
+		XMLName              xml.Name   `xml:"ListPartsResult"`
+		Bucket               string     `xml:"Bucket"`
+		Key                  string     `xml:"Key"`
+		UploadId             string     `xml:"UploadId"`
+		MaxParts             int        `xml:"MaxParts"`
+		IsTruncated          bool       `xml:"IsTruncated"`
+		NextPartNumberMarker int        `xml:"NextPartNumberMarker"`
+		Parts                []fakePart `xml:"Part"`
+	}{Bucket: r.bucket, Key: upload.key, UploadId: query.Get("uploadId"), MaxParts: maxParts}
+	for _, number := range numbers {
+		if len(result.Parts) == maxParts {
+			result.IsTruncated = true
+			break
+		}
+
+		sum := md5.Sum(upload.parts[number])
+		result.Parts = append(result.Parts, fakePart{PartNumber: number, ETag: `"` + hex.EncodeToString(sum[:]) + `"`, Size: len(upload.parts[number])})
+		result.NextPartNumberMarker = number
+	}
+
+	writeFakeXML(w, result)
+}
+
+func (r *fakeS3) completeMultipartUpload(w http.ResponseWriter, req *http.Request, key string, query url.Values) {
+	upload, ok := r.uploads[query.Get("uploadId")]
+	if !ok {
+		writeFakeError(w, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist.")
+		return
+	}
+
+	var input struct {
+		Parts []struct {
+			ETag       string `xml:"ETag"`
+			PartNumber int    `xml:"PartNumber"`
+		} `xml:"Part"`
+	}
+	if err := xml.NewDecoder(req.Body).Decode(&input); err != nil {
+		writeFakeError(w, http.StatusBadRequest, "MalformedXML", err.Error())
+		return
+	}
+	if len(input.Parts) == 0 {
+		writeFakeError(w, http.StatusBadRequest, "MalformedXML", "The XML you provided was not well-formed or did not validate against our published schema.")
+		return
+	}
+
+	var data, sums []byte
+	for i, part := range input.Parts {
+		content, ok := upload.parts[part.PartNumber]
+		sum := md5.Sum(content)
+		if !ok || part.ETag != `"`+hex.EncodeToString(sum[:])+`"` {
+			writeFakeError(w, http.StatusBadRequest, "InvalidPart", "One or more of the specified parts could not be found.")
+			return
+		}
+		if i < len(input.Parts)-1 && len(content) < minPartSize {
+			writeFakeError(w, http.StatusBadRequest, "EntityTooSmall", "Your proposed upload is smaller than the minimum allowed object size.")
+			return
+		}
+
+		data = append(data, content...)
+		sums = append(sums, sum[:]...)
+	}
+
+	object := r.store(key, data, upload.contentType)
+	object.acl = upload.acl
+	object.headers = upload.headers
+	object.tags = upload.tags
+	sum := md5.Sum(sums)
+	object.etag = fmt.Sprintf(`"%s-%d"`, hex.EncodeToString(sum[:]), len(input.Parts))
+	delete(r.uploads, query.Get("uploadId"))
+
+	writeFakeXML(w, struct {
+		XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+		Bucket  string   `xml:"Bucket"`
+		Key     string   `xml:"Key"`
+		ETag    string   `xml:"ETag"`
+	}{Bucket: r.bucket, Key: key, ETag: object.etag})
+}
+
+// readFakeBody reads the request body, decoding the aws-chunked encoding used by streaming signatures.
+func readFakeBody(req *http.Request) ([]byte, error) {
+	if !strings.Contains(req.Header.Get("Content-Encoding"), "aws-chunked") &&
+		!strings.HasPrefix(req.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(req.Body)
+	}
+
+	var data []byte
+	reader := bufio.NewReader(req.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		size, err := strconv.ParseInt(strings.TrimSpace(strings.SplitN(line, ";", 2)[0]), 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return data, nil
+		}
+
+		chunk := make([]byte, size)
+		if _, err := io.ReadFull(reader, chunk); err != nil {
+			return nil, err
+		}
+		data = append(data, chunk...)
+		if _, err := reader.Discard(2); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func writeFakeXML(w http.ResponseWriter, value any) {
+	data, err := xml.Marshal(value)
+	if err != nil {
+		writeFakeError(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(data)
+}
+
+func writeFakeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, "%s<Error><Code>%s</Code><Message>%s</Message></Error>", xml.Header, code, message)
+}
@@ -0,0 +1,100 @@
+package s3
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+
+	"github.com/goravel/framework/contracts/config"
+)
+
+// newHTTPClient builds the HTTP client of the disk from filesystems.disks.<disk>.http, nil is returned when none of
+// the keys is set so the default client of the SDK is kept.
+func newHTTPClient(config config.Config, disk string) (aws.HTTPClient, error) {
+	timeout := config.GetDuration(fmt.Sprintf("filesystems.disks.%s.http.timeout", disk))
+	dialTimeout := config.GetDuration(fmt.Sprintf("filesystems.disks.%s.http.dial_timeout", disk))
+	tlsHandshakeTimeout := config.GetDuration(fmt.Sprintf("filesystems.disks.%s.http.tls_handshake_timeout", disk))
+	responseHeaderTimeout := config.GetDuration(fmt.Sprintf("filesystems.disks.%s.http.response_header_timeout", disk))
+	idleConnTimeout := config.GetDuration(fmt.Sprintf("filesystems.disks.%s.http.idle_conn_timeout", disk))
+	proxy := config.GetString(fmt.Sprintf("filesystems.disks.%s.http.proxy", disk))
+	caBundle := config.GetString(fmt.Sprintf("filesystems.disks.%s.http.ca_bundle", disk))
+	maxIdleConns := config.GetInt(fmt.Sprintf("filesystems.disks.%s.http.max_idle_conns", disk))
+	maxIdleConnsPerHost := config.GetInt(fmt.Sprintf("filesystems.disks.%s.http.max_idle_conns_per_host", disk))
+	maxConnsPerHost := config.GetInt(fmt.Sprintf("filesystems.disks.%s.http.max_conns_per_host", disk))
+
+	if timeout <= 0 && dialTimeout <= 0 && tlsHandshakeTimeout <= 0 && responseHeaderTimeout <= 0 && idleConnTimeout <= 0 &&
+		proxy == "" && caBundle == "" && maxIdleConns <= 0 && maxIdleConnsPerHost <= 0 && maxConnsPerHost <= 0 {
+		return nil, nil
+	}
+
+	var proxyURL *url.URL
+	if proxy != "" {
+		var err error
+		if proxyURL, err = url.Parse(proxy); err != nil {
+			return nil, fmt.Errorf("invalid http.proxy of %s: %w", disk, err)
+		}
+	}
+
+	var rootCAs *x509.CertPool
+	if caBundle != "" {
+		pem, err := os.ReadFile(caBundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read http.ca_bundle of %s: %w", disk, err)
+		}
+
+		// The bundle is added to the system certificates, so the public endpoints of AWS remain trusted.
+		if rootCAs, err = x509.SystemCertPool(); err != nil {
+			rootCAs = x509.NewCertPool()
+		}
+		if !rootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in http.ca_bundle of %s", disk)
+		}
+	}
+
+	client := awshttp.NewBuildableClient().WithTransportOptions(func(transport *http.Transport) {
+		if tlsHandshakeTimeout > 0 {
+			transport.TLSHandshakeTimeout = tlsHandshakeTimeout
+		}
+		if responseHeaderTimeout > 0 {
+			transport.ResponseHeaderTimeout = responseHeaderTimeout
+		}
+		if idleConnTimeout > 0 {
+			transport.IdleConnTimeout = idleConnTimeout
+		}
+		if proxyURL != nil {
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+		if rootCAs != nil {
+			if transport.TLSClientConfig == nil {
+				transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+			}
+			transport.TLSClientConfig.RootCAs = rootCAs
+		}
+		if maxIdleConns > 0 {
+			transport.MaxIdleConns = maxIdleConns
+		}
+		if maxIdleConnsPerHost > 0 {
+			transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+		}
+		if maxConnsPerHost > 0 {
+			transport.MaxConnsPerHost = maxConnsPerHost
+		}
+	})
+	if dialTimeout > 0 {
+		client = client.WithDialerOptions(func(dialer *net.Dialer) {
+			dialer.Timeout = dialTimeout
+		})
+	}
+	if timeout > 0 {
+		client = client.WithTimeout(timeout)
+	}
+
+	return client, nil
+}
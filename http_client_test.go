@@ -0,0 +1,121 @@
+package s3
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingTransport counts the requests sent through it.
+type countingTransport struct {
+	requests atomic.Int32
+}
+
+func (r *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.requests.Add(1)
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestHTTPClient(t *testing.T) {
+	fake := newFakeS3(t)
+	transport := &countingTransport{}
+
+	values := map[string]any{
+		"app.timezone":                  "UTC",
+		"filesystems.disks.s3.key":      "key",
+		"filesystems.disks.s3.secret":   "secret",
+		"filesystems.disks.s3.region":   "us-east-1",
+		"filesystems.disks.s3.bucket":   fake.bucket,
+		"filesystems.disks.s3.url":      "https://goravel.dev",
+		"filesystems.disks.s3.endpoint": fake.server.URL,
+		// The keys are ignored when a client is injected.
+		"filesystems.disks.s3.http.proxy": "http://127.0.0.1:1",
+	}
+	driver, err := NewS3(context.Background(), newMockConfig(t, values), "s3", WithHTTPClient(&http.Client{Transport: transport}))
+	assert.Nil(t, err)
+	assert.Nil(t, driver.Put("HTTPClient.txt", "Goravel"))
+	assert.Equal(t, int32(1), transport.requests.Load())
+
+	configTransport := &countingTransport{}
+	values["filesystems.disks.s3.http.client"] = &http.Client{Transport: configTransport}
+	driver, err = NewS3(context.Background(), newMockConfig(t, values), "s3", WithHTTPClient(&http.Client{Transport: transport}))
+	assert.Nil(t, err)
+	assert.Nil(t, driver.Put("HTTPClient.txt", "Goravel"))
+	assert.Equal(t, int32(1), transport.requests.Load())
+	assert.Equal(t, int32(1), configTransport.requests.Load())
+}
+
+func TestHTTPClient_Proxy(t *testing.T) {
+	fake := newFakeS3(t)
+	proxy := httptest.NewServer(http.HandlerFunc(fake.handle))
+	t.Cleanup(proxy.Close)
+
+	// The endpoint can only be reached through the proxy.
+	driver, _ := newFakeDriver(t, map[string]any{
+		"filesystems.disks.s3.endpoint":   "http://s3.goravel.invalid",
+		"filesystems.disks.s3.http.proxy": proxy.URL,
+	})
+	assert.Nil(t, driver.Put("Proxy.txt", "Goravel"))
+	assert.Equal(t, []string{"Proxy.txt"}, fake.keys())
+}
+
+func TestHTTPClient_CABundle(t *testing.T) {
+	fake := newFakeS3(t)
+	server := httptest.NewTLSServer(http.HandlerFunc(fake.handle))
+	t.Cleanup(server.Close)
+
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	assert.Nil(t, os.WriteFile(caBundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600))
+
+	driver, _ := newFakeDriver(t, map[string]any{
+		"filesystems.disks.s3.endpoint": server.URL,
+	})
+	assert.ErrorContains(t, driver.Put("CABundle.txt", "Goravel"), "certificate")
+
+	driver, _ = newFakeDriver(t, map[string]any{
+		"filesystems.disks.s3.endpoint":       server.URL,
+		"filesystems.disks.s3.http.ca_bundle": caBundle,
+	})
+	assert.Nil(t, driver.Put("CABundle.txt", "Goravel"))
+	assert.Equal(t, []string{"CABundle.txt"}, fake.keys())
+
+	_, err := NewS3(context.Background(), newMockConfig(t, map[string]any{
+		"filesystems.disks.s3.key":            "key",
+		"filesystems.disks.s3.secret":         "secret",
+		"filesystems.disks.s3.region":         "us-east-1",
+		"filesystems.disks.s3.bucket":         "goravel",
+		"filesystems.disks.s3.url":            "https://goravel.dev",
+		"filesystems.disks.s3.http.ca_bundle": filepath.Join(t.TempDir(), "missing.pem"),
+	}), "s3")
+	assert.ErrorContains(t, err, "failed to read http.ca_bundle of s3")
+}
+
+func TestHTTPClient_Timeout(t *testing.T) {
+	fake := newFakeS3(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		fake.handle(w, req)
+	}))
+	t.Cleanup(server.Close)
+
+	driver, _ := newFakeDriver(t, map[string]any{
+		"filesystems.disks.s3.endpoint":                     server.URL,
+		"filesystems.disks.s3.http.response_header_timeout": 50 * time.Millisecond,
+	})
+	assert.ErrorContains(t, driver.Put("Timeout.txt", "Goravel"), "timeout")
+
+	driver, _ = newFakeDriver(t, map[string]any{
+		"filesystems.disks.s3.endpoint":     server.URL,
+		"filesystems.disks.s3.http.timeout": time.Second,
+	})
+	assert.Nil(t, driver.Put("Timeout.txt", "Goravel"))
+}
@@ -0,0 +1,97 @@
+package s3
+
+import (
+	"maps"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Metadata gets the user-defined metadata of a file, S3 returns the keys in lowercase.
+func (r *S3) Metadata(file string) (map[string]string, error) {
+	resp, err := r.headObject(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return cloneMetadata(resp.Metadata), nil
+}
+
+// UpdateMetadata replaces the user-defined metadata of a file by copying the file onto itself. The content type,
+// the other headers, the storage class, the encryption, the tags and the visibility are kept. S3 doesn't copy the
+// ACL, so the file gets the canned ACL its visibility is mapped to, visibility.public or visibility.private. An
+// Expires header that isn't an HTTP date can't be copied and is dropped. ErrPreconditionFailed is returned if the file is changed in the meantime.
+func (r *S3) UpdateMetadata(file string, metadata map[string]string) error {
+	head, err := r.headObject(file)
+	if err != nil {
+		return err
+	}
+	visibility, err := r.Visibility(file)
+	if err != nil {
+		return err
+	}
+	acl, err := r.visibilityACL(visibility)
+	if err != nil {
+		return err
+	}
+
+	input := &s3.CopyObjectInput{
+		Bucket:                  aws.String(r.bucket),
+		CopySource:              aws.String(r.copySource(file)),
+		CopySourceIfMatch:       head.ETag,
+		Key:                     aws.String(file),
+		Metadata:                metadata,
+		MetadataDirective:       types.MetadataDirectiveReplace,
+		CacheControl:            head.CacheControl,
+		ContentDisposition:      head.ContentDisposition,
+		ContentEncoding:         head.ContentEncoding,
+		ContentLanguage:         head.ContentLanguage,
+		ContentType:             head.ContentType,
+		WebsiteRedirectLocation: head.WebsiteRedirectLocation,
+		StorageClass:            head.StorageClass,
+		ServerSideEncryption:    head.ServerSideEncryption,
+		SSEKMSKeyId:             head.SSEKMSKeyId,
+		BucketKeyEnabled:        head.BucketKeyEnabled,
+		ACL:                     types.ObjectCannedACL(acl),
+	}
+	if expires, err := http.ParseTime(aws.ToString(head.ExpiresString)); err == nil {
+		input.Expires = aws.Time(expires)
+	}
+
+	_, err = r.instance.CopyObject(r.ctx, input)
+	r.stats.forget(file)
+	if err != nil {
+		return r.wrapError(file, err)
+	}
+
+	return nil
+}
+
+// cloneMetadata clones the metadata of a response, an empty map is returned instead of nil so it can be written.
+func cloneMetadata(metadata map[string]string) map[string]string {
+	if metadata == nil {
+		return make(map[string]string)
+	}
+
+	return maps.Clone(metadata)
+}
+
+// headObject gets the attributes of a file, the options change the input, the error is wrapped.
+func (r *S3) headObject(file string, opts ...func(*s3.HeadObjectInput)) (*s3.HeadObjectOutput, error) {
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(r.bucket),
+		Key:    aws.String(file),
+	}
+	for _, opt := range opts {
+		opt(input)
+	}
+
+	resp, err := r.instance.HeadObject(r.ctx, input)
+	if err != nil {
+		return nil, r.wrapError(file, err)
+	}
+
+	return resp, nil
+}
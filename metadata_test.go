@@ -0,0 +1,150 @@
+package s3
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadata(t *testing.T) {
+	driver, fake := newFakeDriver(t)
+	expires := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	assert.Nil(t, driver.Put("Metadata/1.txt", "Goravel"))
+	metadata, err := driver.Metadata("Metadata/1.txt")
+	assert.Nil(t, err)
+	assert.Empty(t, metadata)
+
+	assert.Nil(t, driver.PutWithOptions("Metadata/2.csv", "a,b",
+		WithContentType("text/csv"),
+		WithCacheControl("no-cache"),
+		WithContentDisposition(`attachment; filename="report.csv"`),
+		WithStorageClass("STANDARD_IA"),
+		WithExpires(expires),
+		WithTags(map[string]string{"retention": "30d"}),
+		WithMetadata(map[string]string{"Original-Name": "report.csv", "uploader": "1"}),
+	))
+	metadata, err = driver.Metadata("Metadata/2.csv")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"original-name": "report.csv", "uploader": "1"}, metadata)
+
+	assert.Nil(t, driver.UpdateMetadata("Metadata/2.csv", map[string]string{"uploader": "2", "checksum": "abc"}))
+	metadata, err = driver.Metadata("Metadata/2.csv")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"uploader": "2", "checksum": "abc"}, metadata)
+
+	// The content and the other attributes are kept.
+	content, err := driver.Get("Metadata/2.csv")
+	assert.Nil(t, err)
+	assert.Equal(t, "a,b", content)
+	object := fake.objects["Metadata/2.csv"]
+	assert.Equal(t, "text/csv", object.contentType)
+	assert.Equal(t, http.Header{
+		"Cache-Control":       {"no-cache"},
+		"Content-Disposition": {`attachment; filename="report.csv"`},
+		"Expires":             {expires.Format(http.TimeFormat)},
+		"X-Amz-Meta-Checksum": {"abc"},
+		"X-Amz-Meta-Uploader": {"2"},
+		"X-Amz-Storage-Class": {"STANDARD_IA"},
+	}, object.headers)
+	tags, err := driver.Tags("Metadata/2.csv")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"retention": "30d"}, tags)
+
+	// An empty map removes the metadata.
+	assert.Nil(t, driver.UpdateMetadata("Metadata/2.csv", nil))
+	metadata, err = driver.Metadata("Metadata/2.csv")
+	assert.Nil(t, err)
+	assert.Empty(t, metadata)
+
+	_, err = driver.Metadata("Metadata/3.txt")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+	assert.ErrorIs(t, driver.UpdateMetadata("Metadata/3.txt", map[string]string{"uploader": "1"}), ErrObjectNotFound)
+	assert.NotContains(t, fake.keys(), "Metadata/3.txt")
+}
+
+func TestUpdateMetadata_Visibility(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[string]any
+	}{
+		{
+			name: "without object_canned_acl",
+		},
+		{
+			name:   "with a public object_canned_acl",
+			values: map[string]any{"filesystems.disks.s3.object_canned_acl": "public-read"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			driver, fake := newFakeDriver(t, test.values)
+
+			assert.Nil(t, driver.Put("Metadata/1.txt", "Goravel"))
+			assert.Nil(t, driver.SetVisibility("Metadata/1.txt", VisibilityPublic))
+			assert.Nil(t, driver.PutWithOptions("Metadata/2.txt", "Goravel", WithVisibility(VisibilityPrivate)))
+
+			for file, visibility := range map[string]string{"Metadata/1.txt": VisibilityPublic, "Metadata/2.txt": VisibilityPrivate} {
+				assert.Nil(t, driver.UpdateMetadata(file, map[string]string{"uploader": "1"}))
+				got, err := driver.Visibility(file)
+				assert.Nil(t, err)
+				assert.Equal(t, visibility, got, file)
+			}
+			assert.Equal(t, "public-read", fake.objects["Metadata/1.txt"].acl)
+			assert.Equal(t, "private", fake.objects["Metadata/2.txt"].acl)
+		})
+	}
+}
+
+func TestUpdateMetadata_EscapedKey(t *testing.T) {
+	driver, fake := newFakeDriver(t)
+
+	// A plus is read as a space by S3 if it isn't escaped, so the other file would be copied.
+	file := "Metadata/a+b c%25?é.txt"
+	assert.Nil(t, driver.Put(file, "Goravel"))
+	assert.Nil(t, driver.Put("Metadata/a b c%25?é.txt", "Other"))
+
+	assert.Nil(t, driver.UpdateMetadata(file, map[string]string{"uploader": "1"}))
+	metadata, err := driver.Metadata(file)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"uploader": "1"}, metadata)
+	assert.Equal(t, "Goravel", string(fake.objects[file].data))
+
+	assert.Nil(t, driver.Copy(file, "Metadata/copy.txt"))
+	assert.Equal(t, "Goravel", string(fake.objects["Metadata/copy.txt"].data))
+}
+
+// racingTransport calls before ahead of the first request sending a copy source.
+type racingTransport struct {
+	before func()
+}
+
+func (r *racingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.before != nil && req.Header.Get("X-Amz-Copy-Source") != "" {
+		r.before()
+		r.before = nil
+	}
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestUpdateMetadata_Changed(t *testing.T) {
+	transport := &racingTransport{}
+	driver, fake := newFakeDriver(t, map[string]any{
+		"filesystems.disks.s3.http.client": &http.Client{Transport: transport},
+	})
+
+	assert.Nil(t, driver.PutWithOptions("Metadata/1.txt", "Goravel", WithMetadata(map[string]string{"uploader": "1"})))
+	// The file is replaced between the HeadObject and the CopyObject of the update.
+	transport.before = func() {
+		fake.put("Metadata/1.txt", []byte("Goravel 2"))
+	}
+
+	assert.ErrorIs(t, driver.UpdateMetadata("Metadata/1.txt", map[string]string{"uploader": "2"}), ErrPreconditionFailed)
+	content, err := driver.Get("Metadata/1.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "Goravel 2", content)
+}
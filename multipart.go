@@ -0,0 +1,183 @@
+package s3
+
+import (
+	"cmp"
+	"io"
+	"iter"
+	"mime"
+	"path"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// MultipartUpload is the state of a resumable upload. It can be persisted, e.g. as JSON, and resumed
+// after a restart with ResumeUpload.
+type MultipartUpload struct {
+	Key      string `json:"key"`
+	UploadID string `json:"upload_id"`
+	// PartSize is the size of every part except the last one.
+	PartSize int64          `json:"part_size"`
+	Parts    []UploadedPart `json:"parts"`
+}
+
+// UploadedPart is a part of a multipart upload that has been uploaded.
+type UploadedPart struct {
+	PartNumber int32  `json:"part_number"`
+	ETag       string `json:"etag"`
+	Size       int64  `json:"size"`
+}
+
+// CreateUpload starts a resumable multipart upload of the file, the content type is guessed from
+// the file extension unless it's set by WithContentType.
+func (r *S3) CreateUpload(file string, opts ...PutOption) (*MultipartUpload, error) {
+	options, err := r.newPutOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.makeParentDirectories(file); err != nil {
+		return nil, err
+	}
+
+	if options.contentType == "" {
+		options.contentType = mime.TypeByExtension(path.Ext(file))
+	}
+	if options.contentType == "" {
+		options.contentType = "application/octet-stream"
+	}
+
+	upload, err := r.instance.CreateMultipartUpload(r.ctx, r.createMultipartUploadInput(file, options))
+	if err != nil {
+		return nil, err
+	}
+
+	return &MultipartUpload{
+		Key:      file,
+		UploadID: aws.ToString(upload.UploadId),
+		PartSize: r.multipart.partSize,
+	}, nil
+}
+
+// ResumeUpload rebuilds the state of an unfinished upload from the parts stored by S3, the parts
+// uploaded before a crash are kept even if they were not persisted.
+func (r *S3) ResumeUpload(file, uploadID string) (*MultipartUpload, error) {
+	upload := &MultipartUpload{
+		Key:      file,
+		UploadID: uploadID,
+		PartSize: r.multipart.partSize,
+	}
+
+	paginator := s3.NewListPartsPaginator(r.instance, &s3.ListPartsInput{
+		Bucket:   aws.String(r.bucket),
+		Key:      aws.String(file),
+		UploadId: aws.String(uploadID),
+	})
+	for paginator.HasMorePages() {
+		listPartsResponse, err := paginator.NextPage(r.ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, part := range listPartsResponse.Parts {
+			upload.Parts = append(upload.Parts, UploadedPart{
+				PartNumber: aws.ToInt32(part.PartNumber),
+				ETag:       aws.ToString(part.ETag),
+				Size:       aws.ToInt64(part.Size),
+			})
+		}
+	}
+
+	sortParts(upload.Parts)
+	// Every part except the last one has the part size the upload was started with.
+	if len(upload.Parts) > 1 || (len(upload.Parts) == 1 && upload.Parts[0].Size > upload.PartSize) {
+		upload.PartSize = upload.Parts[0].Size
+	}
+
+	return upload, nil
+}
+
+// UploadPart uploads the whole body as a part of the upload, uploading an existing part number again
+// replaces it.
+func (r *S3) UploadPart(upload *MultipartUpload, partNumber int32, body io.ReadSeeker) error {
+	size, err := body.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	etag, err := r.putPart(r.ctx, upload.Key, aws.String(upload.UploadID), contentPart{number: partNumber, body: body, size: size})
+	if err != nil {
+		return err
+	}
+
+	upload.addParts(UploadedPart{PartNumber: partNumber, ETag: aws.ToString(etag), Size: size})
+
+	return nil
+}
+
+// UploadFrom uploads concurrently the parts of the content that are missing from the upload, the
+// content is split with the part size of the upload. The successful parts are added to the upload
+// even if an error is returned.
+func (r *S3) UploadFrom(upload *MultipartUpload, content io.ReaderAt, size int64) error {
+	uploaded := make(map[int32]int64, len(upload.Parts))
+	for _, part := range upload.Parts {
+		uploaded[part.PartNumber] = part.Size
+	}
+
+	var missingParts iter.Seq2[contentPart, error] = func(yield func(contentPart, error) bool) {
+		for part, err := range sectionParts(content, size, upload.PartSize) {
+			if partSize, ok := uploaded[part.number]; ok && err == nil && partSize == part.size {
+				continue
+			}
+			if !yield(part, err) {
+				return
+			}
+		}
+	}
+
+	uploadedParts, err := r.putParts(upload.Key, aws.String(upload.UploadID), missingParts)
+	upload.addParts(uploadedParts...)
+
+	return err
+}
+
+// CompleteUpload assembles the uploaded parts into the file.
+func (r *S3) CompleteUpload(upload *MultipartUpload) error {
+	return r.completeMultipartUpload(upload.Key, aws.String(upload.UploadID), upload.Parts)
+}
+
+// AbortUpload aborts the upload and releases the uploaded parts.
+func (r *S3) AbortUpload(upload *MultipartUpload) error {
+	_, err := r.instance.AbortMultipartUpload(r.ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(r.bucket),
+		Key:      aws.String(upload.Key),
+		UploadId: aws.String(upload.UploadID),
+	})
+
+	return err
+}
+
+// addParts adds or replaces the parts of the upload, keeping them in part number order.
+func (r *MultipartUpload) addParts(parts ...UploadedPart) {
+	for _, part := range parts {
+		index := slices.IndexFunc(r.Parts, func(uploadedPart UploadedPart) bool {
+			return uploadedPart.PartNumber == part.PartNumber
+		})
+		if index >= 0 {
+			r.Parts[index] = part
+		} else {
+			r.Parts = append(r.Parts, part)
+		}
+	}
+
+	sortParts(r.Parts)
+}
+
+func sortParts(parts []UploadedPart) {
+	slices.SortFunc(parts, func(a, b UploadedPart) int {
+		return cmp.Compare(a.PartNumber, b.PartNumber)
+	})
+}
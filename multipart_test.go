@@ -0,0 +1,76 @@
+package s3
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResumableUpload(t *testing.T) {
+	driver, fake := newFakeDriver(t, multipartConfigValues())
+	content := make([]byte, 12*1024*1024)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+
+	upload, err := driver.CreateUpload("Resumable/backup.zip")
+	assert.Nil(t, err)
+	assert.Equal(t, "Resumable/backup.zip", upload.Key)
+	assert.NotEmpty(t, upload.UploadID)
+	assert.Equal(t, int64(minPartSize), upload.PartSize)
+	assert.Equal(t, "application/zip", fake.uploads[upload.UploadID].contentType)
+
+	assert.Nil(t, driver.UploadPart(upload, 1, bytes.NewReader(content[:minPartSize])))
+	assert.Len(t, upload.Parts, 1)
+	state, err := json.Marshal(upload)
+	assert.Nil(t, err)
+
+	// The second part is uploaded by the worker before it's preempted, without being persisted.
+	assert.Nil(t, driver.UploadPart(upload, 2, bytes.NewReader(content[minPartSize:2*minPartSize])))
+
+	var persisted MultipartUpload
+	assert.Nil(t, json.Unmarshal(state, &persisted))
+	assert.Len(t, persisted.Parts, 1)
+
+	resumed, err := driver.ResumeUpload(persisted.Key, persisted.UploadID)
+	assert.Nil(t, err)
+	assert.Equal(t, upload, resumed)
+
+	assert.Nil(t, driver.UploadFrom(resumed, bytes.NewReader(content), int64(len(content))))
+	assert.Len(t, resumed.Parts, 3)
+	assert.Equal(t, 3, fake.count("UploadPart"))
+
+	assert.Nil(t, driver.CompleteUpload(resumed))
+	assert.Equal(t, content, fake.objects["Resumable/backup.zip"].data)
+	assert.Equal(t, "application/zip", fake.objects["Resumable/backup.zip"].contentType)
+	assert.True(t, driver.Exists("Resumable/"))
+}
+
+func TestResumableUpload_Failure(t *testing.T) {
+	values := multipartConfigValues()
+	values["filesystems.disks.s3.multipart.max_retries"] = 0
+	values["filesystems.disks.s3.multipart.concurrency"] = 1
+	driver, fake := newFakeDriver(t, values)
+	content := make([]byte, 12*1024*1024)
+
+	upload, err := driver.CreateUpload("Resumable.bin")
+	assert.Nil(t, err)
+	assert.Equal(t, "application/octet-stream", fake.uploads[upload.UploadID].contentType)
+
+	assert.Nil(t, driver.UploadPart(upload, 1, bytes.NewReader(content[:minPartSize])))
+	fake.fail("UploadPart", 1, http.StatusBadRequest, "BadDigest")
+	assert.NotNil(t, driver.UploadFrom(upload, bytes.NewReader(content), int64(len(content))))
+	assert.Len(t, upload.Parts, 1)
+
+	assert.Nil(t, driver.UploadFrom(upload, bytes.NewReader(content), int64(len(content))))
+	assert.Len(t, upload.Parts, 3)
+	assert.Equal(t, 4, fake.count("UploadPart"))
+
+	assert.Nil(t, driver.AbortUpload(upload))
+	assert.Empty(t, fake.uploads)
+	_, err = driver.ResumeUpload(upload.Key, upload.UploadID)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,40 @@
+package s3
+
+import (
+	"time"
+)
+
+// ObjectInfo describes an object, or a directory when IsDir is true, stored on the disk. The listings only set
+// Key, Size, ETag, LastModified, StorageClass and IsDir, Stat sets every field.
+type ObjectInfo struct {
+	// Key is the full key of the object in the bucket.
+	Key          string
+	Size         int64
+	ETag         string
+	LastModified time.Time
+	StorageClass string
+	IsDir        bool
+	ContentType  string
+	// VersionID is empty when the bucket isn't versioned.
+	VersionID string
+	// Metadata is the user-defined metadata, S3 returns the keys in lowercase.
+	Metadata map[string]string
+	// ServerSideEncryption is the encryption algorithm, e.g. AES256 or aws:kms, SSEKMSKeyID is set for aws:kms.
+	ServerSideEncryption string
+	SSEKMSKeyID          string
+	// Checksum is the base64 encoded checksum S3 stored with the object, ChecksumAlgorithm is its algorithm,
+	// e.g. CRC32 or SHA256. A checksum of a multipart upload may be a checksum of the part checksums.
+	ChecksumAlgorithm string
+	Checksum          string
+}
+
+// IterateOptions controls how Iterate walks the objects under a path.
+type IterateOptions struct {
+	// Recursive walks every object under the path, otherwise only the direct children
+	// are returned, with the sub directories yielded as IsDir entries.
+	Recursive bool
+	// StartAfter is the full key after which the listing starts.
+	StartAfter string
+	// MaxKeys limits the number of entries yielded, 0 means no limit.
+	MaxKeys int
+}
@@ -0,0 +1,42 @@
+package s3
+
+import (
+	"net/http"
+
+	"github.com/goravel/framework/support/color"
+)
+
+// Logger receives the errors the driver can't return, the log facade of Goravel satisfies it.
+type Logger interface {
+	Errorf(format string, args ...any)
+}
+
+// Option configures the driver created by NewS3.
+type Option func(*driverOptions)
+
+type driverOptions struct {
+	httpClient *http.Client
+	logger     Logger
+}
+
+// WithHTTPClient sets the HTTP client of the driver instead of the one built from filesystems.disks.<disk>.http,
+// the client set by filesystems.disks.<disk>.http.client takes precedence.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *driverOptions) {
+		o.httpClient = client
+	}
+}
+
+// WithLogger sets the logger of the driver, the logger set by filesystems.disks.<disk>.logger takes precedence.
+func WithLogger(logger Logger) Option {
+	return func(o *driverOptions) {
+		o.logger = logger
+	}
+}
+
+// colorLogger prints the errors to the console, it's used when no logger is set.
+type colorLogger struct{}
+
+func (r colorLogger) Errorf(format string, args ...any) {
+	color.Red().Printfln(format, args...)
+}
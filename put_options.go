@@ -0,0 +1,152 @@
+package s3
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// PutOption sets an attribute of the object written by PutStream, PutWithOptions, PutFileAsWithOptions or
+// CreateUpload.
+type PutOption func(*putOptions)
+
+type putOptions struct {
+	// acl is the canned ACL of the object, from the visibility or the object_canned_acl of the disk.
+	acl                string
+	cacheControl       string
+	contentDisposition string
+	contentEncoding    string
+	contentType        string
+	expires            time.Time
+	metadata           map[string]string
+	storageClass       string
+	tags               map[string]string
+	visibility         string
+}
+
+// newPutOptions applies the options, the visibility is replaced by the canned ACL it's mapped to.
+func (r *S3) newPutOptions(opts []PutOption) (putOptions, error) {
+	var options putOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	options.acl = r.objectCannedACL
+	if options.visibility != "" {
+		acl, err := r.visibilityACL(options.visibility)
+		if err != nil {
+			return options, err
+		}
+		options.acl = acl
+	}
+
+	return options, nil
+}
+
+// WithCacheControl sets the Cache-Control header of the object.
+func WithCacheControl(cacheControl string) PutOption {
+	return func(o *putOptions) {
+		o.cacheControl = cacheControl
+	}
+}
+
+// WithContentDisposition sets the Content-Disposition header of the object, e.g. to set the download filename.
+func WithContentDisposition(contentDisposition string) PutOption {
+	return func(o *putOptions) {
+		o.contentDisposition = contentDisposition
+	}
+}
+
+// WithContentEncoding sets the Content-Encoding header of the object, the content is stored as is.
+func WithContentEncoding(contentEncoding string) PutOption {
+	return func(o *putOptions) {
+		o.contentEncoding = contentEncoding
+	}
+}
+
+// WithContentType sets the Content-Type header of the object instead of detecting it from the content.
+func WithContentType(contentType string) PutOption {
+	return func(o *putOptions) {
+		o.contentType = contentType
+	}
+}
+
+// WithExpires sets the Expires header of the object.
+func WithExpires(expires time.Time) PutOption {
+	return func(o *putOptions) {
+		o.expires = expires
+	}
+}
+
+// WithMetadata sets the user-defined metadata of the object, S3 returns the keys in lowercase.
+func WithMetadata(metadata map[string]string) PutOption {
+	return func(o *putOptions) {
+		o.metadata = metadata
+	}
+}
+
+// WithStorageClass sets the storage class of the object, e.g. STANDARD_IA or GLACIER_IR.
+func WithStorageClass(storageClass string) PutOption {
+	return func(o *putOptions) {
+		o.storageClass = storageClass
+	}
+}
+
+// putObjectInput returns the input of a PutObject of the file, the body is set by the caller.
+func (r *S3) putObjectInput(file string, options putOptions) *s3.PutObjectInput {
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(r.bucket),
+		Key:    aws.String(file),
+	}
+	if options.acl != "" {
+		input.ACL = types.ObjectCannedACL(options.acl)
+	}
+	if options.cacheControl != "" {
+		input.CacheControl = aws.String(options.cacheControl)
+	}
+	if options.contentDisposition != "" {
+		input.ContentDisposition = aws.String(options.contentDisposition)
+	}
+	if options.contentEncoding != "" {
+		input.ContentEncoding = aws.String(options.contentEncoding)
+	}
+	if options.contentType != "" {
+		input.ContentType = aws.String(options.contentType)
+	}
+	if !options.expires.IsZero() {
+		input.Expires = aws.Time(options.expires)
+	}
+	if len(options.metadata) > 0 {
+		input.Metadata = options.metadata
+	}
+	if options.storageClass != "" {
+		input.StorageClass = types.StorageClass(options.storageClass)
+	}
+	if len(options.tags) > 0 {
+		input.Tagging = aws.String(encodeTags(options.tags))
+	}
+
+	return input
+}
+
+// createMultipartUploadInput returns the input of a CreateMultipartUpload of the file. The attributes are copied
+// from the input of a PutObject, so they are only set in putObjectInput.
+func (r *S3) createMultipartUploadInput(file string, options putOptions) *s3.CreateMultipartUploadInput {
+	input := r.putObjectInput(file, options)
+
+	return &s3.CreateMultipartUploadInput{
+		Bucket:             input.Bucket,
+		Key:                input.Key,
+		ACL:                input.ACL,
+		CacheControl:       input.CacheControl,
+		ContentDisposition: input.ContentDisposition,
+		ContentEncoding:    input.ContentEncoding,
+		ContentType:        input.ContentType,
+		Expires:            input.Expires,
+		Metadata:           input.Metadata,
+		StorageClass:       input.StorageClass,
+		Tagging:            input.Tagging,
+	}
+}
@@ -0,0 +1,137 @@
+package s3
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPutWithOptions(t *testing.T) {
+	expires := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	opts := []PutOption{
+		WithContentType("text/csv"),
+		WithCacheControl("public, max-age=3600"),
+		WithContentDisposition(`attachment; filename="report.csv"`),
+		WithContentEncoding("gzip"),
+		WithMetadata(map[string]string{"uploader": "1", "original-name": "report.csv"}),
+		WithExpires(expires),
+		WithStorageClass("STANDARD_IA"),
+		WithTags(map[string]string{"retention": "30d"}),
+		WithVisibility(VisibilityPublic),
+	}
+	headers := http.Header{
+		"Cache-Control":            {"public, max-age=3600"},
+		"Content-Disposition":      {`attachment; filename="report.csv"`},
+		"Content-Encoding":         {"gzip"},
+		"Expires":                  {expires.Format(http.TimeFormat)},
+		"X-Amz-Meta-Original-Name": {"report.csv"},
+		"X-Amz-Meta-Uploader":      {"1"},
+		"X-Amz-Storage-Class":      {"STANDARD_IA"},
+	}
+	large := bytes.Repeat([]byte("a"), 11*1024*1024)
+
+	tests := []struct {
+		name string
+		put  func(driver *S3) (string, error)
+	}{
+		{
+			name: "PutWithOptions",
+			put: func(driver *S3) (string, error) {
+				return "PutWithOptions/1.csv", driver.PutWithOptions("PutWithOptions/1.csv", "a,b", opts...)
+			},
+		},
+		{
+			name: "PutStream with a reader",
+			put: func(driver *S3) (string, error) {
+				return "PutWithOptions/2.csv", driver.PutStream("PutWithOptions/2.csv", onlyReader{strings.NewReader("a,b")}, opts...)
+			},
+		},
+		{
+			name: "PutStream with a multipart upload",
+			put: func(driver *S3) (string, error) {
+				return "PutWithOptions/3.csv", driver.PutStream("PutWithOptions/3.csv", bytes.NewReader(large), opts...)
+			},
+		},
+		{
+			name: "PutStream with a multipart upload of a reader",
+			put: func(driver *S3) (string, error) {
+				return "PutWithOptions/4.csv", driver.PutStream("PutWithOptions/4.csv", onlyReader{bytes.NewReader(large)}, opts...)
+			},
+		},
+		{
+			name: "PutFileAsWithOptions",
+			put: func(driver *S3) (string, error) {
+				return driver.PutFileAsWithOptions("PutWithOptions", &File{path: "logo.png"}, "logo", opts...)
+			},
+		},
+		{
+			name: "CreateUpload",
+			put: func(driver *S3) (string, error) {
+				upload, err := driver.CreateUpload("PutWithOptions/5.csv", opts...)
+				if err != nil {
+					return "", err
+				}
+				if err := driver.UploadPart(upload, 1, strings.NewReader("a,b")); err != nil {
+					return "", err
+				}
+
+				return upload.Key, driver.CompleteUpload(upload)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			driver, fake := newFakeDriver(t, multipartConfigValues())
+
+			file, err := test.put(driver)
+			assert.Nil(t, err)
+			assert.Equal(t, "text/csv", fake.objects[file].contentType)
+			assert.Equal(t, headers, fake.objects[file].headers)
+			assert.Equal(t, url.Values{"retention": {"30d"}}, fake.objects[file].tags)
+			assert.Equal(t, "public-read", fake.objects[file].acl)
+		})
+	}
+}
+
+func TestCreateMultipartUploadInput(t *testing.T) {
+	driver, _ := newFakeDriver(t)
+	options, err := driver.newPutOptions([]PutOption{
+		WithContentType("text/csv"),
+		WithCacheControl("no-cache"),
+		WithContentDisposition("attachment"),
+		WithContentEncoding("gzip"),
+		WithMetadata(map[string]string{"uploader": "1"}),
+		WithExpires(time.Now()),
+		WithStorageClass("STANDARD_IA"),
+		WithTags(map[string]string{"retention": "30d"}),
+		WithVisibility(VisibilityPublic),
+	})
+	assert.Nil(t, err)
+
+	// Every attribute set on a PutObject is also set on a CreateMultipartUpload.
+	putInput := reflect.ValueOf(*driver.putObjectInput("1.csv", options))
+	uploadInput := reflect.ValueOf(*driver.createMultipartUploadInput("1.csv", options))
+	for i := range putInput.NumField() {
+		field := putInput.Type().Field(i)
+		if !field.IsExported() || putInput.Field(i).IsZero() {
+			continue
+		}
+
+		assert.Equal(t, putInput.Field(i).Interface(), uploadInput.FieldByName(field.Name).Interface(), field.Name)
+	}
+}
+
+func TestPutWithOptions_Default(t *testing.T) {
+	driver, fake := newFakeDriver(t)
+
+	assert.Nil(t, driver.PutWithOptions("PutWithOptions/1.txt", "Goravel"))
+	assert.Equal(t, "text/plain; charset=utf-8", fake.objects["PutWithOptions/1.txt"].contentType)
+	assert.Empty(t, fake.objects["PutWithOptions/1.txt"].headers)
+}
@@ -0,0 +1,165 @@
+package s3
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// readAheadSize is the minimum number of bytes fetched by a ranged request of an ObjectReader.
+const readAheadSize = 64 * 1024
+
+// ObjectReader reads an object through ranged requests, it implements io.Reader, io.ReaderAt and io.Seeker.
+// The reads fail if the object is replaced after the reader is opened.
+type ObjectReader struct {
+	driver *S3
+	etag   string
+	key    string
+	offset int64
+	size   int64
+
+	lock         sync.Mutex
+	buffer       []byte
+	bufferOffset int64
+}
+
+// GetRange gets length bytes of a file from the offset, a length lower than 1 reads to the end of the file.
+func (r *S3) GetRange(file string, offset, length int64) ([]byte, error) {
+	return r.getRange(file, offset, length, "")
+}
+
+// OpenReaderAt opens the file for random access reads, only the requested ranges are downloaded.
+func (r *S3) OpenReaderAt(file string) (*ObjectReader, error) {
+	resp, err := r.headObject(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ObjectReader{
+		driver: r,
+		etag:   aws.ToString(resp.ETag),
+		key:    file,
+		size:   aws.ToInt64(resp.ContentLength),
+	}, nil
+}
+
+func (r *S3) getRange(file string, offset, length int64, etag string) ([]byte, error) {
+	// S3 ignores a malformed range and returns the whole file.
+	if offset < 0 {
+		return nil, errors.New("s3: negative offset")
+	}
+
+	byteRange := fmt.Sprintf("bytes=%d-", offset)
+	if length > 0 {
+		byteRange = fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+	}
+
+	getObjectInput := &s3.GetObjectInput{
+		Bucket: aws.String(r.bucket),
+		Key:    aws.String(file),
+		Range:  aws.String(byteRange),
+	}
+	if etag != "" {
+		getObjectInput.IfMatch = aws.String(etag)
+	}
+
+	resp, err := r.instance.GetObject(r.ctx, getObjectInput)
+	if err != nil {
+		return nil, r.wrapError(file, err)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := resp.Body.Close(); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// Read reads from the current offset.
+func (r *ObjectReader) Read(p []byte) (int, error) {
+	n, err := r.ReadAt(p, r.offset)
+	r.offset += int64(n)
+	if n > 0 && errors.Is(err, io.EOF) {
+		err = nil
+	}
+
+	return n, err
+}
+
+// ReadAt reads len(p) bytes from the offset, the bytes are served from the read-ahead buffer when possible.
+func (r *ObjectReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("s3: negative offset")
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+
+	end := min(off+int64(len(p)), r.size)
+	r.lock.Lock()
+	if off >= r.bufferOffset && end <= r.bufferOffset+int64(len(r.buffer)) {
+		n := copy(p, r.buffer[off-r.bufferOffset:end-r.bufferOffset])
+		r.lock.Unlock()
+
+		return n, readAtEOF(n, p)
+	}
+	r.lock.Unlock()
+
+	data, err := r.driver.getRange(r.key, off, min(max(end-off, readAheadSize), r.size-off), r.etag)
+	if err != nil {
+		return 0, err
+	}
+
+	r.lock.Lock()
+	r.buffer, r.bufferOffset = data, off
+	r.lock.Unlock()
+
+	n := copy(p, data)
+	if n < int(end-off) {
+		return n, io.ErrUnexpectedEOF
+	}
+
+	return n, readAtEOF(n, p)
+}
+
+// Seek sets the offset of the next Read.
+func (r *ObjectReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, errors.New("s3: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("s3: negative position")
+	}
+
+	r.offset = offset
+
+	return offset, nil
+}
+
+// Size returns the size of the object.
+func (r *ObjectReader) Size() int64 {
+	return r.size
+}
+
+// readAtEOF returns io.EOF when p can't be filled because the end of the object is reached.
+func readAtEOF(n int, p []byte) error {
+	if n < len(p) {
+		return io.EOF
+	}
+
+	return nil
+}
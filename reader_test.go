@@ -0,0 +1,115 @@
+package s3
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRange(t *testing.T) {
+	driver, fake := newFakeDriver(t)
+	fake.put("GetRange/1.txt", []byte("Hello Goravel"))
+
+	data, err := driver.GetRange("GetRange/1.txt", 6, 7)
+	assert.Nil(t, err)
+	assert.Equal(t, "Goravel", string(data))
+
+	data, err = driver.GetRange("GetRange/1.txt", 6, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, "Goravel", string(data))
+
+	data, err = driver.GetRange("GetRange/1.txt", 0, 100)
+	assert.Nil(t, err)
+	assert.Equal(t, "Hello Goravel", string(data))
+
+	_, err = driver.GetRange("GetRange/1.txt", 100, 1)
+	assert.NotNil(t, err)
+
+	requests := fake.count("GetObject")
+	_, err = driver.GetRange("GetRange/1.txt", -5, 10)
+	assert.EqualError(t, err, "s3: negative offset")
+	_, err = driver.GetRange("GetRange/1.txt", -5, -1)
+	assert.EqualError(t, err, "s3: negative offset")
+	assert.Equal(t, requests, fake.count("GetObject"))
+}
+
+func TestObjectReader(t *testing.T) {
+	driver, fake := newFakeDriver(t)
+	content := make([]byte, 3*readAheadSize)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+	fake.put("ObjectReader/1.bin", content)
+
+	reader, err := driver.OpenReaderAt("ObjectReader/1.bin")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(content)), reader.Size())
+
+	p := make([]byte, 10)
+	n, err := reader.ReadAt(p, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 10, n)
+	assert.Equal(t, content[:10], p)
+	assert.Equal(t, 1, fake.count("GetObject"))
+
+	// The bytes are served from the read-ahead buffer.
+	n, err = reader.ReadAt(p, 100)
+	assert.Nil(t, err)
+	assert.Equal(t, 10, n)
+	assert.Equal(t, content[100:110], p)
+	assert.Equal(t, 1, fake.count("GetObject"))
+
+	n, err = reader.ReadAt(p, int64(len(content)-5))
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, 5, n)
+	assert.Equal(t, content[len(content)-5:], p[:5])
+	assert.Equal(t, 2, fake.count("GetObject"))
+
+	n, err = reader.ReadAt(p, int64(len(content)))
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, 0, n)
+
+	offset, err := reader.Seek(-20, io.SeekEnd)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(content)-20), offset)
+	data, err := io.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, content[len(content)-20:], data)
+
+	_, err = reader.Seek(0, io.SeekStart)
+	assert.Nil(t, err)
+	data, err = io.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, content, data)
+
+	// The reads fail once the object is replaced.
+	fake.put("ObjectReader/1.bin", []byte("Goravel"))
+	_, err = reader.ReadAt(p, int64(len(content)/2))
+	assert.NotNil(t, err)
+}
+
+func TestObjectReader_Zip(t *testing.T) {
+	driver, fake := newFakeDriver(t)
+
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	for _, name := range []string{"1.txt", "2.txt"} {
+		file, err := writer.Create(name)
+		assert.Nil(t, err)
+		_, err = file.Write(bytes.Repeat([]byte("Goravel"), readAheadSize))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, writer.Close())
+	fake.put("ObjectReader/1.zip", buffer.Bytes())
+
+	reader, err := driver.OpenReaderAt("ObjectReader/1.zip")
+	assert.Nil(t, err)
+	archive, err := zip.NewReader(reader, reader.Size())
+	assert.Nil(t, err)
+	assert.Len(t, archive.File, 2)
+	assert.Equal(t, "2.txt", archive.File[1].Name)
+	assert.Equal(t, uint64(7*readAheadSize), archive.File[1].UncompressedSize64)
+}
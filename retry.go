@@ -0,0 +1,93 @@
+package s3
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+
+	"github.com/goravel/framework/contracts/config"
+)
+
+// defaultRetryCodes are retried besides the codes the SDK retries. S3 may answer a CopyObject or a
+// CompleteMultipartUpload with InternalError after it has sent a 200 status.
+var defaultRetryCodes = []string{"InternalError"}
+
+// retryConfig is read from filesystems.disks.<disk>.retry, the profile settings are used for the keys that aren't set.
+type retryConfig struct {
+	maxAttempts int
+	maxBackoff  time.Duration
+	mode        aws.RetryMode
+	// codes are the retried error codes, including the throttling codes of the SDK.
+	codes map[string]struct{}
+}
+
+func newRetryConfig(config config.Config, disk string, sharedConfig aws.Config) (retryConfig, error) {
+	retryConfig := retryConfig{
+		maxAttempts: config.GetInt(fmt.Sprintf("filesystems.disks.%s.retry.max_attempts", disk)),
+		maxBackoff:  config.GetDuration(fmt.Sprintf("filesystems.disks.%s.retry.max_backoff", disk)),
+		mode:        aws.RetryMode(config.GetString(fmt.Sprintf("filesystems.disks.%s.retry.mode", disk))),
+		codes:       make(map[string]struct{}),
+	}
+	if retryConfig.maxAttempts <= 0 {
+		retryConfig.maxAttempts = sharedConfig.RetryMaxAttempts
+	}
+	if retryConfig.maxAttempts <= 0 {
+		retryConfig.maxAttempts = retry.DefaultMaxAttempts
+	}
+	if retryConfig.maxBackoff <= 0 {
+		retryConfig.maxBackoff = retry.DefaultMaxBackoff
+	}
+	if retryConfig.mode == "" {
+		retryConfig.mode = sharedConfig.RetryMode
+	}
+	if retryConfig.mode == "" {
+		retryConfig.mode = aws.RetryModeStandard
+	}
+	if retryConfig.mode != aws.RetryModeStandard && retryConfig.mode != aws.RetryModeAdaptive {
+		return retryConfig, fmt.Errorf("unsupported retry.mode %q of %s, it should be standard or adaptive", retryConfig.mode, disk)
+	}
+
+	for code := range retry.DefaultRetryableErrorCodes {
+		retryConfig.codes[code] = struct{}{}
+	}
+	for code := range retry.DefaultThrottleErrorCodes {
+		retryConfig.codes[code] = struct{}{}
+	}
+	for _, code := range append(defaultRetryCodes, configStrings(config, fmt.Sprintf("filesystems.disks.%s.retry.codes", disk))...) {
+		retryConfig.codes[code] = struct{}{}
+	}
+
+	return retryConfig, nil
+}
+
+// retryer returns the retryer of the client, the requests are retried with a jittered exponential backoff.
+func (r retryConfig) retryer() aws.Retryer {
+	standardOptions := func(options *retry.StandardOptions) {
+		options.MaxAttempts = r.maxAttempts
+		options.MaxBackoff = r.maxBackoff
+		options.Backoff = retry.NewExponentialJitterBackoff(r.maxBackoff)
+		options.Retryables = append(options.Retryables, retry.RetryableErrorCode{Codes: r.codes})
+	}
+
+	if r.mode == aws.RetryModeAdaptive {
+		return retry.NewAdaptiveMode(func(options *retry.AdaptiveModeOptions) {
+			options.StandardOptions = append(options.StandardOptions, standardOptions)
+		})
+	}
+
+	return retry.NewStandard(standardOptions)
+}
+
+// retryable determines if a failure with the error code is retried.
+func (r retryConfig) retryable(code string) bool {
+	_, ok := r.codes[code]
+
+	return ok
+}
+
+// delay returns the jittered delay before the given retry attempt.
+func (r retryConfig) delay(attempt int) time.Duration {
+	return min(backoff(attempt), r.maxBackoff)
+}
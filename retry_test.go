@@ -0,0 +1,115 @@
+package s3
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRetryConfig(t *testing.T) {
+	retryConfig, err := newRetryConfig(newMockConfig(t, nil), "s3", aws.Config{})
+	assert.Nil(t, err)
+	assert.Equal(t, retry.DefaultMaxAttempts, retryConfig.maxAttempts)
+	assert.Equal(t, retry.DefaultMaxBackoff, retryConfig.maxBackoff)
+	assert.Equal(t, aws.RetryModeStandard, retryConfig.mode)
+	assert.True(t, retryConfig.retryable("SlowDown"))
+	assert.True(t, retryConfig.retryable("InternalError"))
+	assert.False(t, retryConfig.retryable("AccessDenied"))
+
+	// The profile settings are used for the keys that aren't set.
+	retryConfig, err = newRetryConfig(newMockConfig(t, map[string]any{
+		"filesystems.disks.s3.retry.max_backoff": time.Second,
+		"filesystems.disks.s3.retry.codes":       []string{"OperationAborted"},
+	}), "s3", aws.Config{RetryMaxAttempts: 5, RetryMode: aws.RetryModeAdaptive})
+	assert.Nil(t, err)
+	assert.Equal(t, 5, retryConfig.maxAttempts)
+	assert.Equal(t, time.Second, retryConfig.maxBackoff)
+	assert.Equal(t, aws.RetryModeAdaptive, retryConfig.mode)
+	assert.True(t, retryConfig.retryable("OperationAborted"))
+	assert.True(t, retryConfig.retryable("SlowDown"))
+	assert.LessOrEqual(t, retryConfig.delay(10), time.Second)
+
+	retryConfig, err = newRetryConfig(newMockConfig(t, map[string]any{
+		"filesystems.disks.s3.retry.max_attempts": 8,
+		"filesystems.disks.s3.retry.mode":         "standard",
+	}), "s3", aws.Config{RetryMaxAttempts: 5, RetryMode: aws.RetryModeAdaptive})
+	assert.Nil(t, err)
+	assert.Equal(t, 8, retryConfig.maxAttempts)
+	assert.Equal(t, aws.RetryModeStandard, retryConfig.mode)
+
+	_, err = newRetryConfig(newMockConfig(t, map[string]any{
+		"filesystems.disks.s3.retry.mode": "legacy",
+	}), "s3", aws.Config{})
+	assert.EqualError(t, err, `unsupported retry.mode "legacy" of s3, it should be standard or adaptive`)
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		mode   string
+		status int
+		code   string
+	}{
+		{mode: "standard", status: http.StatusServiceUnavailable, code: "SlowDown"},
+		// The adaptive mode slows the client down after a throttling error, so the test uses another error.
+		{mode: "adaptive", status: http.StatusInternalServerError, code: "InternalError"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.mode, func(t *testing.T) {
+			driver, fake := newFakeDriver(t, map[string]any{
+				"filesystems.disks.s3.retry.max_attempts": 4,
+				"filesystems.disks.s3.retry.mode":         test.mode,
+				"filesystems.disks.s3.retry.codes":        []any{"OperationAborted"},
+			})
+			fake.put("Retry/1.txt", []byte("Goravel"))
+
+			fake.fail("CopyObject", 3, test.status, test.code)
+			assert.Nil(t, driver.Copy("Retry/1.txt", "Retry/2.txt"))
+			assert.Equal(t, 4, fake.count("CopyObject"))
+
+			fake.fail("CopyObject", 4, test.status, test.code)
+			assert.ErrorContains(t, driver.Copy("Retry/1.txt", "Retry/3.txt"), test.code)
+			assert.Equal(t, 8, fake.count("CopyObject"))
+
+			// A code of retry.codes is retried.
+			fake.fail("CopyObject", 1, http.StatusConflict, "OperationAborted")
+			assert.Nil(t, driver.Copy("Retry/1.txt", "Retry/3.txt"))
+			assert.Equal(t, 10, fake.count("CopyObject"))
+
+			fake.fail("CopyObject", 1, http.StatusConflict, "BucketAlreadyOwnedByYou")
+			assert.NotNil(t, driver.Copy("Retry/1.txt", "Retry/4.txt"))
+			assert.Equal(t, 11, fake.count("CopyObject"))
+		})
+	}
+}
+
+func TestRetry_DeleteSlowDown(t *testing.T) {
+	driver, fake := newFakeDriver(t, map[string]any{
+		"filesystems.disks.s3.retry.max_attempts": 3,
+	})
+	for _, key := range []string{"Retry/1.txt", "Retry/2.txt", "Retry/3.txt"} {
+		fake.put(key, []byte("Goravel"))
+	}
+
+	// Only the keys failing with SlowDown are sent again.
+	fake.slowDowns["Retry/1.txt"] = 2
+	fake.slowDowns["Retry/2.txt"] = 1
+	assert.Nil(t, driver.Delete("Retry/1.txt", "Retry/2.txt"))
+	assert.Equal(t, 3, fake.count("DeleteObjects"))
+	assert.Equal(t, []string{"Retry/3.txt"}, fake.keys())
+
+	fake.put("Retry/1.txt", []byte("Goravel"))
+	fake.slowDowns["Retry/1.txt"] = 3
+	fake.denied["Retry/3.txt"] = true
+	var deleteErr *DeleteError
+	assert.ErrorAs(t, driver.Delete("Retry/1.txt", "Retry/3.txt"), &deleteErr)
+	assert.Equal(t, []DeleteFailure{
+		{Key: "Retry/1.txt", Code: "SlowDown", Message: "Please reduce your request rate."},
+		{Key: "Retry/3.txt", Code: "AccessDenied", Message: "Access Denied"},
+	}, deleteErr.Failures)
+	assert.Equal(t, 6, fake.count("DeleteObjects"))
+}
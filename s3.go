@@ -2,20 +2,18 @@ package s3
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"iter"
+	nethttp "net/http"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/aws/aws-sdk-go-v2/service/s3/types"
-	"github.com/gabriel-vasile/mimetype"
 	"github.com/goravel/framework/http"
-	"github.com/goravel/framework/support/color"
 	"github.com/goravel/framework/support/str"
 
 	"github.com/goravel/framework/contracts/config"
@@ -29,82 +27,166 @@ import (
  */
 
 type S3 struct {
-	bucket          string
-	cdn             string
-	config          config.Config
-	ctx             context.Context
-	disk            string
-	instance        *s3.Client
-	objectCannedACL string
-	url             string
-}
-
-func NewS3(ctx context.Context, config config.Config, disk string) (*S3, error) {
-	accessKeyId := config.GetString(fmt.Sprintf("filesystems.disks.%s.key", disk))
-	accessKeySecret := config.GetString(fmt.Sprintf("filesystems.disks.%s.secret", disk))
+	bucket               string
+	cdn                  string
+	config               config.Config
+	ctx                  context.Context
+	deleteConcurrency    int
+	deleteGuard          DeleteGuard
+	deleteGuardThreshold int
+	disk                 string
+	instance             *s3.Client
+	logger               Logger
+	multipart            multipartConfig
+	objectCannedACL      string
+	retry                retryConfig
+	statChecksum         bool
+	stats                *statCache
+	url                  string
+	visibility           visibilityConfig
+}
+
+func NewS3(ctx context.Context, config config.Config, disk string, opts ...Option) (*S3, error) {
 	region := config.GetString(fmt.Sprintf("filesystems.disks.%s.region", disk))
 	bucket := config.GetString(fmt.Sprintf("filesystems.disks.%s.bucket", disk))
 	url := config.GetString(fmt.Sprintf("filesystems.disks.%s.url", disk))
-	token := config.GetString(fmt.Sprintf("filesystems.disks.%s.token", disk))
 	endpoint := config.GetString(fmt.Sprintf("filesystems.disks.%s.endpoint", disk))
 	usePathStyle := config.GetBool(fmt.Sprintf("filesystems.disks.%s.use_path_style", disk), true)
 	cdn := config.GetString(fmt.Sprintf("filesystems.disks.%s.cdn", disk))
 	objectCannedACL := config.GetString(fmt.Sprintf("filesystems.disks.%s.object_canned_acl", disk))
+	visibility := visibilityConfig{
+		public:  config.GetString(fmt.Sprintf("filesystems.disks.%s.visibility.public", disk), defaultPublicACL),
+		private: config.GetString(fmt.Sprintf("filesystems.disks.%s.visibility.private", disk), defaultPrivateACL),
+	}
+	deleteConcurrency := config.GetInt(fmt.Sprintf("filesystems.disks.%s.delete.concurrency", disk), defaultDeleteConcurrency)
+	deleteGuardThreshold := config.GetInt(fmt.Sprintf("filesystems.disks.%s.delete_guard.threshold", disk))
+	statCacheTTL := config.GetDuration(fmt.Sprintf("filesystems.disks.%s.stat.cache_ttl", disk))
+	statChecksum := config.GetBool(fmt.Sprintf("filesystems.disks.%s.stat.checksum", disk), false)
+	var deleteGuard DeleteGuard
+	switch guard := config.Get(fmt.Sprintf("filesystems.disks.%s.delete_guard.callback", disk)).(type) {
+	case DeleteGuard:
+		deleteGuard = guard
+	case func(keys []string) error:
+		deleteGuard = guard
+	}
+	multipart := multipartConfig{
+		threshold:   int64(config.GetInt(fmt.Sprintf("filesystems.disks.%s.multipart.threshold", disk), defaultMultipartThreshold)),
+		partSize:    int64(config.GetInt(fmt.Sprintf("filesystems.disks.%s.multipart.part_size", disk), defaultMultipartPartSize)),
+		concurrency: config.GetInt(fmt.Sprintf("filesystems.disks.%s.multipart.concurrency", disk), defaultMultipartConcurrency),
+		maxRetries:  config.GetInt(fmt.Sprintf("filesystems.disks.%s.multipart.max_retries", disk), defaultMultipartMaxRetries),
+	}
+
+	var driverOpts driverOptions
+	for _, opt := range opts {
+		opt(&driverOpts)
+	}
+	if logger, ok := config.Get(fmt.Sprintf("filesystems.disks.%s.logger", disk)).(Logger); ok {
+		driverOpts.logger = logger
+	}
+	if driverOpts.logger == nil {
+		driverOpts.logger = colorLogger{}
+	}
+
+	// The HTTP client is built first, so the requests sent to load the shared configuration use it too.
+	if client, ok := config.Get(fmt.Sprintf("filesystems.disks.%s.http.client", disk)).(*nethttp.Client); ok {
+		driverOpts.httpClient = client
+	}
+	var httpClient aws.HTTPClient
+	if driverOpts.httpClient != nil {
+		httpClient = driverOpts.httpClient
+	} else {
+		client, err := newHTTPClient(config, disk)
+		if err != nil {
+			return nil, err
+		}
+		httpClient = client
+	}
 
-	if accessKeyId == "" || accessKeySecret == "" || region == "" || bucket == "" || url == "" {
+	sharedConfig, err := loadSharedConfig(ctx, config, disk, region, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	if sharedConfig.Region == "" || bucket == "" || url == "" {
 		return nil, fmt.Errorf("please set %s configuration first", disk)
 	}
 
-	options := s3.Options{
-		Region: region,
-		Credentials: aws.NewCredentialsCache(
-			credentials.NewStaticCredentialsProvider(accessKeyId, accessKeySecret, token)),
+	credentialsProvider, err := newCredentialsProvider(ctx, config, disk, sharedConfig)
+	if err != nil {
+		return nil, err
 	}
 
-	if endpoint != "" {
-		options.BaseEndpoint = aws.String(endpoint)
+	retryConfig, err := newRetryConfig(config, disk, sharedConfig)
+	if err != nil {
+		return nil, err
 	}
 
-	if !usePathStyle {
-		options.UsePathStyle = usePathStyle
+	if deleteConcurrency < 1 {
+		deleteConcurrency = 1
+	}
+	if multipart.partSize < minPartSize {
+		multipart.partSize = minPartSize
+	}
+	if multipart.concurrency < 1 {
+		multipart.concurrency = 1
+	}
+	if multipart.maxRetries < 0 {
+		multipart.maxRetries = 0
 	}
 
-	client := s3.New(options)
+	client := s3.NewFromConfig(sharedConfig, func(options *s3.Options) {
+		options.Credentials = credentialsProvider
+		options.Retryer = retryConfig.retryer()
+		// The retryer already holds the attempts of the profile.
+		options.RetryMaxAttempts = 0
+		options.RetryMode = ""
+		if endpoint != "" {
+			options.BaseEndpoint = aws.String(endpoint)
+		}
+		if !usePathStyle {
+			options.UsePathStyle = usePathStyle
+		}
+	})
 
 	return &S3{
-		bucket:          bucket,
-		cdn:             cdn,
-		config:          config,
-		ctx:             ctx,
-		disk:            disk,
-		instance:        client,
-		objectCannedACL: objectCannedACL,
-		url:             url,
+		bucket:               bucket,
+		cdn:                  cdn,
+		config:               config,
+		ctx:                  ctx,
+		deleteConcurrency:    deleteConcurrency,
+		deleteGuard:          deleteGuard,
+		deleteGuardThreshold: deleteGuardThreshold,
+		disk:                 disk,
+		instance:             client,
+		logger:               driverOpts.logger,
+		multipart:            multipart,
+		objectCannedACL:      objectCannedACL,
+		retry:                retryConfig,
+		statChecksum:         statChecksum,
+		stats:                newStatCache(statCacheTTL),
+		url:                  url,
+		visibility:           visibility,
 	}, nil
 }
 
 func (r *S3) AllDirectories(path string) ([]string, error) {
 	var directories []string
 	validPath := validPath(path)
-	listObjsResponse, err := r.instance.ListObjectsV2(r.ctx, &s3.ListObjectsV2Input{
-		Bucket:    aws.String(r.bucket),
-		Delimiter: aws.String("/"),
-		Prefix:    aws.String(validPath),
-	})
-	if err != nil {
-		return nil, err
-	}
+	for object, err := range r.Iterate(path, IterateOptions{}) {
+		if err != nil {
+			return nil, err
+		}
+		if !object.IsDir {
+			continue
+		}
 
-	for _, commonPrefix := range listObjsResponse.CommonPrefixes {
-		prefix := *commonPrefix.Prefix
-		directories = append(directories, strings.ReplaceAll(prefix, validPath, ""))
+		directories = append(directories, strings.ReplaceAll(object.Key, validPath, ""))
 
-		subDirectories, err := r.AllDirectories(*commonPrefix.Prefix)
+		subDirectories, err := r.AllDirectories(object.Key)
 		if err != nil {
 			return nil, err
 		}
 		for _, subDirectory := range subDirectories {
-			directories = append(directories, strings.ReplaceAll(prefix+subDirectory, validPath, ""))
+			directories = append(directories, strings.ReplaceAll(object.Key+subDirectory, validPath, ""))
 		}
 	}
 
@@ -114,50 +196,68 @@ func (r *S3) AllDirectories(path string) ([]string, error) {
 func (r *S3) AllFiles(path string) ([]string, error) {
 	var files []string
 	validPath := validPath(path)
-	listObjsResponse, err := r.instance.ListObjectsV2(r.ctx, &s3.ListObjectsV2Input{
-		Bucket: aws.String(r.bucket),
-		Prefix: aws.String(validPath),
-	})
-	if err != nil {
-		return nil, err
+	for object, err := range r.Iterate(path, IterateOptions{Recursive: true}) {
+		if err != nil {
+			return nil, err
+		}
+		if object.IsDir {
+			continue
+		}
+
+		files = append(files, strings.ReplaceAll(object.Key, validPath, ""))
 	}
-	for _, object := range listObjsResponse.Contents {
-		file := *object.Key
-		if !strings.HasSuffix(file, "/") {
-			files = append(files, strings.ReplaceAll(file, validPath, ""))
+
+	return files, nil
+}
+
+// AllFilesInfo gets all the files with their attributes from the given directory(recursive).
+func (r *S3) AllFilesInfo(path string) ([]ObjectInfo, error) {
+	var files []ObjectInfo
+	for object, err := range r.Iterate(path, IterateOptions{Recursive: true}) {
+		if err != nil {
+			return nil, err
+		}
+		if object.IsDir {
+			continue
 		}
+
+		files = append(files, object)
 	}
 
 	return files, nil
 }
 
+// Check determines if a file exists, an error is returned when the existence can't be determined.
+func (r *S3) Check(file string) (bool, error) {
+	_, err := r.headObject(file)
+	if err == nil {
+		return true, nil
+	}
+
+	if errors.Is(err, ErrObjectNotFound) {
+		return false, nil
+	}
+
+	return false, err
+}
+
 func (r *S3) Copy(originFile, targetFile string) error {
 	_, err := r.instance.CopyObject(r.ctx, &s3.CopyObjectInput{
 		Bucket:     aws.String(r.bucket),
-		CopySource: aws.String(r.bucket + "/" + originFile),
+		CopySource: aws.String(r.copySource(originFile)),
 		Key:        aws.String(targetFile),
 	})
+	r.stats.forget(targetFile)
 
-	return err
+	return r.wrapError(originFile, err)
 }
 
 func (r *S3) Delete(files ...string) error {
-	var objectIdentifiers []types.ObjectIdentifier
-	for _, file := range files {
-		objectIdentifiers = append(objectIdentifiers, types.ObjectIdentifier{
-			Key: aws.String(file),
-		})
+	if err := r.guardDelete(files); err != nil {
+		return err
 	}
 
-	_, err := r.instance.DeleteObjects(r.ctx, &s3.DeleteObjectsInput{
-		Bucket: aws.String(r.bucket),
-		Delete: &types.Delete{
-			Objects: objectIdentifiers,
-			Quiet:   aws.Bool(true),
-		},
-	})
-
-	return err
+	return r.deleteObjects(files)
 }
 
 func (r *S3) DeleteDirectory(directory string) error {
@@ -165,94 +265,102 @@ func (r *S3) DeleteDirectory(directory string) error {
 		directory += "/"
 	}
 
-	listObjectsV2Response, err := r.instance.ListObjectsV2(r.ctx, &s3.ListObjectsV2Input{
-		Bucket: aws.String(r.bucket),
-		Prefix: aws.String(directory),
-	})
-	if err != nil {
-		return err
-	}
-	if len(listObjectsV2Response.Contents) == 0 {
-		return nil
+	// The guard approves the whole listing, so exactly the listed keys are deleted.
+	if r.deleteGuard != nil {
+		keys, err := r.DryRun().DeleteDirectory(directory)
+		if err != nil {
+			return err
+		}
+		if err := r.guardDelete(keys); err != nil {
+			return err
+		}
+
+		return r.deleteObjects(keys)
 	}
 
-	for {
-		for _, item := range listObjectsV2Response.Contents {
-			_, err = r.instance.DeleteObject(r.ctx, &s3.DeleteObjectInput{
-				Bucket: aws.String(r.bucket),
-				Key:    item.Key,
-			})
+	// Every page is deleted while the next one is listed, the listing keeps the prefix on every page.
+	return r.deleteBatches(func(yield func([]string, error) bool) {
+		paginator := s3.NewListObjectsV2Paginator(r.instance, &s3.ListObjectsV2Input{
+			Bucket: aws.String(r.bucket),
+			Prefix: aws.String(directory),
+		})
+		for paginator.HasMorePages() {
+			listObjectsV2Response, err := paginator.NextPage(r.ctx)
 			if err != nil {
-				return err
+				yield(nil, err)
+				return
 			}
-		}
 
-		if *listObjectsV2Response.IsTruncated {
-			listObjectsV2Response, err = r.instance.ListObjectsV2(r.ctx, &s3.ListObjectsV2Input{
-				Bucket:            aws.String(r.bucket),
-				ContinuationToken: listObjectsV2Response.ContinuationToken,
-			})
-			if err != nil {
-				return err
+			keys := make([]string, 0, len(listObjectsV2Response.Contents))
+			for _, item := range listObjectsV2Response.Contents {
+				keys = append(keys, aws.ToString(item.Key))
+			}
+			if !yield(keys, nil) {
+				return
 			}
-		} else {
-			break
 		}
-	}
-
-	return nil
+	})
 }
 
 func (r *S3) Directories(path string) ([]string, error) {
 	var directories []string
 	validPath := validPath(path)
-	listObjsResponse, err := r.instance.ListObjectsV2(r.ctx, &s3.ListObjectsV2Input{
-		Bucket:    aws.String(r.bucket),
-		Delimiter: aws.String("/"),
-		Prefix:    aws.String(validPath),
-	})
-	if err != nil {
-		return nil, err
-	}
-	for _, commonPrefix := range listObjsResponse.CommonPrefixes {
-		directories = append(directories, strings.ReplaceAll(*commonPrefix.Prefix, validPath, ""))
+	for object, err := range r.Iterate(path, IterateOptions{}) {
+		if err != nil {
+			return nil, err
+		}
+		if !object.IsDir {
+			continue
+		}
+
+		directories = append(directories, strings.ReplaceAll(object.Key, validPath, ""))
 	}
 
 	return directories, nil
 }
 
+// Exists determines if a file exists, only a missing file is reported as not existing. The other errors
+// are logged and the file is reported as existing, use Check to handle them.
 func (r *S3) Exists(file string) bool {
-	_, err := r.instance.HeadObject(r.ctx, &s3.HeadObjectInput{
-		Bucket: aws.String(r.bucket),
-		Key:    aws.String(file),
-	})
-
+	exists, err := r.Check(file)
 	if err != nil {
-		log.Println("error while checking file existance:", err)
-		return false
+		r.logger.Errorf("[S3] failed to check the existence of %s: %v", file, err)
+
+		return true
 	}
 
-	return err == nil
+	return exists
 }
 
 func (r *S3) Files(path string) ([]string, error) {
 	var files []string
 	validPath := validPath(path)
-	listObjsResponse, err := r.instance.ListObjectsV2(r.ctx, &s3.ListObjectsV2Input{
-		Bucket:    aws.String(r.bucket),
-		Delimiter: aws.String("/"),
-		Prefix:    aws.String(validPath),
-	})
-	if err != nil {
-		return nil, err
+	for object, err := range r.Iterate(path, IterateOptions{}) {
+		if err != nil {
+			return nil, err
+		}
+		if object.IsDir {
+			continue
+		}
+
+		files = append(files, strings.ReplaceAll(object.Key, validPath, ""))
 	}
-	for _, object := range listObjsResponse.Contents {
-		file := strings.ReplaceAll(*object.Key, validPath, "")
-		if file == "" {
+
+	return files, nil
+}
+
+// FilesInfo gets the files with their attributes from the given directory.
+func (r *S3) FilesInfo(path string) ([]ObjectInfo, error) {
+	var files []ObjectInfo
+	for object, err := range r.Iterate(path, IterateOptions{}) {
+		if err != nil {
+			return nil, err
+		}
+		if object.IsDir {
 			continue
 		}
 
-		files = append(files, file)
+		files = append(files, object)
 	}
 
 	return files, nil
@@ -265,35 +373,91 @@ func (r *S3) Get(file string) (string, error) {
 }
 
 func (r *S3) GetBytes(file string) ([]byte, error) {
-	resp, err := r.instance.GetObject(r.ctx, &s3.GetObjectInput{
-		Bucket: aws.String(r.bucket),
-		Key:    aws.String(file),
-	})
+	body, err := r.GetStream(file)
 	if err != nil {
 		return nil, err
 	}
 
-	data, err := io.ReadAll(resp.Body)
+	data, err := io.ReadAll(body)
 	if err != nil {
 		return nil, err
 	}
-	if err := resp.Body.Close(); err != nil {
+	if err := body.Close(); err != nil {
 		return nil, err
 	}
 
 	return data, nil
 }
 
-func (r *S3) LastModified(file string) (time.Time, error) {
-	resp, err := r.instance.HeadObject(r.ctx, &s3.HeadObjectInput{
+// GetStream gets the contents of a file as a stream, the caller must close it.
+func (r *S3) GetStream(file string) (io.ReadCloser, error) {
+	resp, err := r.instance.GetObject(r.ctx, &s3.GetObjectInput{
 		Bucket: aws.String(r.bucket),
 		Key:    aws.String(file),
 	})
+	if err != nil {
+		return nil, r.wrapError(file, err)
+	}
+
+	return resp.Body, nil
+}
+
+// Iterate walks the objects under the given path page by page, without loading the whole listing in memory.
+func (r *S3) Iterate(path string, options IterateOptions) iter.Seq2[ObjectInfo, error] {
+	return func(yield func(ObjectInfo, error) bool) {
+		location, err := r.location()
+		if err != nil {
+			yield(ObjectInfo{}, err)
+			return
+		}
+
+		validPath := validPath(path)
+		input := &s3.ListObjectsV2Input{
+			Bucket: aws.String(r.bucket),
+			Prefix: aws.String(validPath),
+		}
+		if !options.Recursive {
+			input.Delimiter = aws.String("/")
+		}
+		if options.StartAfter != "" {
+			input.StartAfter = aws.String(options.StartAfter)
+		}
+		if options.MaxKeys > 0 && options.MaxKeys < 1000 {
+			input.MaxKeys = aws.Int32(int32(options.MaxKeys))
+		}
+
+		count := 0
+		paginator := s3.NewListObjectsV2Paginator(r.instance, input)
+		for paginator.HasMorePages() {
+			listObjsResponse, err := paginator.NextPage(r.ctx)
+			if err != nil {
+				yield(ObjectInfo{}, r.wrapError(validPath, err))
+				return
+			}
+
+			for _, object := range mergeListing(listObjsResponse, location) {
+				if object.Key == validPath {
+					continue
+				}
+				if !yield(object, nil) {
+					return
+				}
+				// The walk stops at the limit, without requesting the next page.
+				if count++; options.MaxKeys > 0 && count >= options.MaxKeys {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (r *S3) LastModified(file string) (time.Time, error) {
+	resp, err := r.headObject(file)
 	if err != nil {
 		return time.Time{}, err
 	}
 
-	l, err := time.LoadLocation(r.config.GetString("app.timezone"))
+	l, err := r.location()
 	if err != nil {
 		return time.Time{}, err
 	}
@@ -310,10 +474,7 @@ func (r *S3) MakeDirectory(directory string) error {
 }
 
 func (r *S3) MimeType(file string) (string, error) {
-	resp, err := r.instance.HeadObject(r.ctx, &s3.HeadObjectInput{
-		Bucket: aws.String(r.bucket),
-		Key:    aws.String(file),
-	})
+	resp, err := r.headObject(file)
 	if err != nil {
 		return "", err
 	}
@@ -326,11 +487,15 @@ func (r *S3) Missing(file string) bool {
 }
 
 func (r *S3) Move(oldFile, newFile string) error {
+	// The guard is checked first, so a vetoed move doesn't leave a copy behind.
+	if err := r.guardDelete([]string{oldFile}); err != nil {
+		return err
+	}
 	if err := r.Copy(oldFile, newFile); err != nil {
 		return err
 	}
 
-	return r.Delete(oldFile)
+	return r.deleteObjects([]string{oldFile})
 }
 
 func (r *S3) Path(file string) string {
@@ -338,33 +503,7 @@ func (r *S3) Path(file string) string {
 }
 
 func (r *S3) Put(file string, content string) error {
-	// If the file is created in a folder directly, we can't check if the folder exists.
-	// So we need to create the folders first.
-	if !strings.HasSuffix(file, "/") {
-		folders := strings.Split(file, "/")
-		for i := 1; i < len(folders); i++ {
-			folder := strings.Join(folders[:i], "/")
-			if err := r.MakeDirectory(folder); err != nil {
-				return err
-			}
-		}
-	}
-
-	mtype := mimetype.Detect([]byte(content))
-	putObjectInput := &s3.PutObjectInput{
-		Bucket:        aws.String(r.bucket),
-		Key:           aws.String(file),
-		Body:          strings.NewReader(content),
-		ContentLength: aws.Int64(int64(len(content))),
-		ContentType:   aws.String(mtype.String()),
-	}
-	if r.objectCannedACL != "" {
-		putObjectInput.ACL = types.ObjectCannedACL(r.objectCannedACL)
-	}
-
-	_, err := r.instance.PutObject(r.ctx, putObjectInput)
-
-	return err
+	return r.PutStream(file, strings.NewReader(content))
 }
 
 func (r *S3) PutFile(filePath string, source filesystem.File) (string, error) {
@@ -372,28 +511,54 @@ func (r *S3) PutFile(filePath string, source filesystem.File) (string, error) {
 }
 
 func (r *S3) PutFileAs(filePath string, source filesystem.File, name string) (string, error) {
+	return r.PutFileAsWithOptions(filePath, source, name)
+}
+
+// PutFileAsWithOptions uploads the given file with a new name, the options set the attributes of the object.
+func (r *S3) PutFileAsWithOptions(filePath string, source filesystem.File, name string, opts ...PutOption) (string, error) {
 	fullPath, err := fullPathOfFile(filePath, source, name)
 	if err != nil {
 		return "", err
 	}
 
-	data, err := os.ReadFile(source.File())
+	f, err := os.Open(source.File())
 	if err != nil {
 		return "", err
 	}
+	defer f.Close()
 
-	if err := r.Put(fullPath, string(data)); err != nil {
+	if err := r.PutStream(fullPath, f, opts...); err != nil {
 		return "", err
 	}
 
 	return fullPath, nil
 }
 
+// PutStream writes the contents read from the reader to a file, without buffering the whole content in memory.
+// The MIME type is detected from the first 3 KB of the content unless it's set by WithContentType.
+func (r *S3) PutStream(file string, reader io.Reader, opts ...PutOption) error {
+	options, err := r.newPutOptions(opts)
+	if err != nil {
+		return err
+	}
+	if err := r.makeParentDirectories(file); err != nil {
+		return err
+	}
+
+	if seeker, ok := reader.(io.ReadSeeker); ok {
+		return r.putSeeker(file, seeker, options)
+	}
+
+	return r.putReader(file, reader, options)
+}
+
+// PutWithOptions writes the contents of a file, the options set the attributes of the object.
+func (r *S3) PutWithOptions(file string, content string, opts ...PutOption) error {
+	return r.PutStream(file, strings.NewReader(content), opts...)
+}
+
 func (r *S3) Size(file string) (int64, error) {
-	resp, err := r.instance.HeadObject(r.ctx, &s3.HeadObjectInput{
-		Bucket: aws.String(r.bucket),
-		Key:    aws.String(file),
-	})
+	resp, err := r.headObject(file)
 	if err != nil {
 		return 0, err
 	}
@@ -423,14 +588,11 @@ func (r *S3) WithContext(ctx context.Context) filesystem.Driver {
 		ctx = httpCtx.Context()
 	}
 
-	driver, err := NewS3(ctx, r.config, r.disk)
-	if err != nil {
-		color.Red().Printfln("[S3] init disk error: %+v", err)
-
-		return nil
-	}
+	// The copy shares the client, so its connections and cached credentials are reused.
+	driver := *r
+	driver.ctx = ctx
 
-	return driver
+	return &driver
 }
 
 func (r *S3) Url(file string) string {
@@ -444,3 +606,7 @@ func (r *S3) Url(file string) string {
 
 	return u
 }
+
+func (r *S3) location() (*time.Location, error) {
+	return time.LoadLocation(r.config.GetString("app.timezone"))
+}
@@ -2,6 +2,7 @@ package s3
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"mime"
 	"net/http"
@@ -32,10 +33,42 @@ func TestStorage(t *testing.T) {
 	mockConfig.EXPECT().GetString("filesystems.disks.s3.bucket").Return(os.Getenv("AWS_BUCKET"))
 	mockConfig.EXPECT().GetString("filesystems.disks.s3.url").Return(os.Getenv("AWS_URL"))
 	mockConfig.EXPECT().GetString("filesystems.disks.s3.token").Return("")
+	mockConfig.EXPECT().GetString("filesystems.disks.s3.profile").Return("")
+	mockConfig.EXPECT().Get("filesystems.disks.s3.shared_config_files").Return(nil)
+	mockConfig.EXPECT().Get("filesystems.disks.s3.shared_credentials_files").Return(nil)
+	mockConfig.EXPECT().Get("filesystems.disks.s3.credentials").Return(nil)
+	mockConfig.EXPECT().GetInt("filesystems.disks.s3.retry.max_attempts").Return(0)
+	mockConfig.EXPECT().GetDuration("filesystems.disks.s3.retry.max_backoff").Return(0)
+	mockConfig.EXPECT().GetDuration("filesystems.disks.s3.stat.cache_ttl").Return(0)
+	mockConfig.EXPECT().GetBool("filesystems.disks.s3.stat.checksum", false).Return(false)
+	mockConfig.EXPECT().GetString("filesystems.disks.s3.retry.mode").Return("")
+	mockConfig.EXPECT().Get("filesystems.disks.s3.retry.codes").Return(nil)
+	mockConfig.EXPECT().GetString("filesystems.disks.s3.assume_role.role_arn").Return("")
 	mockConfig.EXPECT().GetString("filesystems.disks.s3.endpoint").Return("")
 	mockConfig.EXPECT().GetBool("filesystems.disks.s3.use_path_style", true).Return(false)
 	mockConfig.EXPECT().GetString("filesystems.disks.s3.cdn").Return("")
 	mockConfig.EXPECT().GetString("filesystems.disks.s3.object_canned_acl").Return("")
+	mockConfig.EXPECT().GetString("filesystems.disks.s3.visibility.public", defaultPublicACL).Return(defaultPublicACL)
+	mockConfig.EXPECT().GetString("filesystems.disks.s3.visibility.private", defaultPrivateACL).Return(defaultPrivateACL)
+	mockConfig.EXPECT().Get("filesystems.disks.s3.logger").Return(nil)
+	mockConfig.EXPECT().Get("filesystems.disks.s3.http.client").Return(nil)
+	mockConfig.EXPECT().GetDuration("filesystems.disks.s3.http.timeout").Return(0)
+	mockConfig.EXPECT().GetDuration("filesystems.disks.s3.http.dial_timeout").Return(0)
+	mockConfig.EXPECT().GetDuration("filesystems.disks.s3.http.tls_handshake_timeout").Return(0)
+	mockConfig.EXPECT().GetDuration("filesystems.disks.s3.http.response_header_timeout").Return(0)
+	mockConfig.EXPECT().GetDuration("filesystems.disks.s3.http.idle_conn_timeout").Return(0)
+	mockConfig.EXPECT().GetString("filesystems.disks.s3.http.proxy").Return("")
+	mockConfig.EXPECT().GetString("filesystems.disks.s3.http.ca_bundle").Return("")
+	mockConfig.EXPECT().GetInt("filesystems.disks.s3.http.max_idle_conns").Return(0)
+	mockConfig.EXPECT().GetInt("filesystems.disks.s3.http.max_idle_conns_per_host").Return(0)
+	mockConfig.EXPECT().GetInt("filesystems.disks.s3.http.max_conns_per_host").Return(0)
+	mockConfig.EXPECT().GetInt("filesystems.disks.s3.delete.concurrency", defaultDeleteConcurrency).Return(defaultDeleteConcurrency)
+	mockConfig.EXPECT().GetInt("filesystems.disks.s3.delete_guard.threshold").Return(0)
+	mockConfig.EXPECT().Get("filesystems.disks.s3.delete_guard.callback").Return(nil)
+	mockConfig.EXPECT().GetInt("filesystems.disks.s3.multipart.threshold", defaultMultipartThreshold).Return(defaultMultipartThreshold)
+	mockConfig.EXPECT().GetInt("filesystems.disks.s3.multipart.part_size", defaultMultipartPartSize).Return(defaultMultipartPartSize)
+	mockConfig.EXPECT().GetInt("filesystems.disks.s3.multipart.concurrency", defaultMultipartConcurrency).Return(defaultMultipartConcurrency)
+	mockConfig.EXPECT().GetInt("filesystems.disks.s3.multipart.max_retries", defaultMultipartMaxRetries).Return(defaultMultipartMaxRetries)
 
 	var driver contractsfilesystem.Driver
 	url := os.Getenv("AWS_URL")
@@ -411,6 +444,199 @@ func TestStorage(t *testing.T) {
 	assert.Nil(t, os.Remove("test.txt"))
 }
 
+func TestListPagination(t *testing.T) {
+	driver, fake := newFakeDriver(t)
+	for i := 0; i < 1200; i++ {
+		fake.put(fmt.Sprintf("Pagination/%04d.txt", i), []byte("Goravel"))
+		fake.put(fmt.Sprintf("Pagination/%04d/1.txt", i), []byte("Goravel"))
+	}
+	for i := 0; i < 1100; i++ {
+		fake.put(fmt.Sprintf("Pagination/0000/%04d/1.txt", i), []byte("Goravel"))
+	}
+	fake.put("Sibling/1.txt", []byte("Goravel"))
+
+	files, err := driver.AllFiles("Pagination")
+	assert.Nil(t, err)
+	assert.Len(t, files, 3500)
+	assert.Equal(t, "0000.txt", files[0])
+	assert.Equal(t, "1199/1.txt", files[len(files)-1])
+
+	files, err = driver.Files("Pagination")
+	assert.Nil(t, err)
+	assert.Len(t, files, 1200)
+	assert.Equal(t, "0000.txt", files[0])
+	assert.Equal(t, "1199.txt", files[len(files)-1])
+
+	directories, err := driver.Directories("Pagination")
+	assert.Nil(t, err)
+	assert.Len(t, directories, 1200)
+	assert.Equal(t, "0000/", directories[0])
+	assert.Equal(t, "1199/", directories[len(directories)-1])
+
+	directories, err = driver.AllDirectories("Pagination")
+	assert.Nil(t, err)
+	assert.Len(t, directories, 2300)
+	assert.Equal(t, "0000/", directories[0])
+	assert.Equal(t, "0000/0000/", directories[1])
+	assert.Equal(t, "1199/", directories[len(directories)-1])
+}
+
+type recordLogger struct {
+	messages []string
+}
+
+func (r *recordLogger) Errorf(format string, args ...any) {
+	r.messages = append(r.messages, fmt.Sprintf(format, args...))
+}
+
+func TestCheck(t *testing.T) {
+	logger := &recordLogger{}
+	driver, fake := newFakeDriver(t, map[string]any{"filesystems.disks.s3.logger": logger})
+	fake.put("Check/1.txt", []byte("Goravel"))
+
+	exists, err := driver.Check("Check/1.txt")
+	assert.Nil(t, err)
+	assert.True(t, exists)
+	assert.True(t, driver.Exists("Check/1.txt"))
+
+	exists, err = driver.Check("Check/2.txt")
+	assert.Nil(t, err)
+	assert.False(t, exists)
+	assert.False(t, driver.Exists("Check/2.txt"))
+	assert.True(t, driver.Missing("Check/2.txt"))
+	assert.Empty(t, logger.messages)
+
+	fake.fail("HeadObject", 1, http.StatusForbidden, "AccessDenied")
+	exists, err = driver.Check("Check/2.txt")
+	assert.ErrorIs(t, err, ErrAccessDenied)
+	assert.False(t, exists)
+
+	// The existence can't be determined, so the file isn't reported as missing.
+	fake.fail("HeadObject", 1, http.StatusForbidden, "AccessDenied")
+	assert.False(t, driver.Missing("Check/2.txt"))
+	assert.Len(t, logger.messages, 1)
+	assert.Contains(t, logger.messages[0], "[S3] failed to check the existence of Check/2.txt: ")
+}
+
+func TestWithLogger(t *testing.T) {
+	logger := &recordLogger{}
+	fake := newFakeS3(t)
+	driver, err := NewS3(context.Background(), newMockConfig(t, map[string]any{
+		"filesystems.disks.s3.key":      "key",
+		"filesystems.disks.s3.secret":   "secret",
+		"filesystems.disks.s3.region":   "us-east-1",
+		"filesystems.disks.s3.bucket":   fake.bucket,
+		"filesystems.disks.s3.url":      "https://goravel.dev",
+		"filesystems.disks.s3.endpoint": fake.server.URL,
+	}), "s3", WithLogger(logger))
+	assert.Nil(t, err)
+
+	fake.fail("HeadObject", 1, http.StatusInternalServerError, "InternalError")
+	fake.fail("HeadObject", -1, http.StatusBadRequest, "InvalidArgument")
+	assert.True(t, driver.Exists("WithLogger/1.txt"))
+	assert.Len(t, logger.messages, 1)
+}
+
+func TestFilesInfo(t *testing.T) {
+	driver, fake := newFakeDriver(t, map[string]any{"app.timezone": "Asia/Shanghai"})
+	fake.put("FilesInfo/", nil)
+	fake.put("FilesInfo/1.txt", []byte("Goravel"))
+	fake.put("FilesInfo/2/", nil)
+	fake.put("FilesInfo/2/2.txt", []byte("Goravel!"))
+
+	files, err := driver.AllFilesInfo("FilesInfo")
+	assert.Nil(t, err)
+	assert.Len(t, files, 2)
+	assert.Equal(t, "FilesInfo/1.txt", files[0].Key)
+	assert.Equal(t, int64(7), files[0].Size)
+	assert.Equal(t, "FilesInfo/2/2.txt", files[1].Key)
+	assert.Equal(t, int64(8), files[1].Size)
+	assert.Equal(t, `"3df179fae3cffdd62e214e9ffd175c32"`, files[1].ETag)
+
+	files, err = driver.FilesInfo("./FilesInfo/")
+	assert.Nil(t, err)
+	assert.Len(t, files, 1)
+	assert.Equal(t, "FilesInfo/1.txt", files[0].Key)
+	assert.Equal(t, "STANDARD", files[0].StorageClass)
+	assert.Equal(t, "Asia/Shanghai", files[0].LastModified.Location().String())
+	assert.Equal(t, fake.objects["FilesInfo/1.txt"].lastModified.Unix(), files[0].LastModified.Unix())
+
+	assert.Equal(t, 0, fake.count("HeadObject"))
+}
+
+func TestGetStream(t *testing.T) {
+	driver, fake := newFakeDriver(t)
+	fake.put("GetStream/1.txt", []byte("Goravel"))
+
+	body, err := driver.GetStream("GetStream/1.txt")
+	assert.Nil(t, err)
+	data, err := io.ReadAll(body)
+	assert.Nil(t, err)
+	assert.Nil(t, body.Close())
+	assert.Equal(t, "Goravel", string(data))
+
+	body, err = driver.GetStream("GetStream/2.txt")
+	assert.NotNil(t, err)
+	assert.Nil(t, body)
+}
+
+func TestIterate(t *testing.T) {
+	driver, fake := newFakeDriver(t)
+	fake.put("Iterate/", nil)
+	fake.put("Iterate/1.txt", []byte("Goravel"))
+	fake.put("Iterate/2.txt", []byte("Goravel"))
+	fake.put("Iterate/2/", nil)
+	fake.put("Iterate/2/3.txt", []byte("Goravel"))
+	fake.put("Iterate/3/4.txt", []byte("Goravel"))
+	fake.put("Iterate1/5.txt", []byte("Goravel"))
+
+	keys := func(options IterateOptions) []string {
+		var keys []string
+		for object, err := range driver.Iterate("Iterate", options) {
+			assert.Nil(t, err)
+			keys = append(keys, object.Key)
+		}
+
+		return keys
+	}
+
+	assert.Equal(t, []string{"Iterate/1.txt", "Iterate/2.txt", "Iterate/2/", "Iterate/2/3.txt", "Iterate/3/4.txt"}, keys(IterateOptions{Recursive: true}))
+	assert.Equal(t, []string{"Iterate/1.txt", "Iterate/2.txt", "Iterate/2/", "Iterate/3/"}, keys(IterateOptions{}))
+	assert.Equal(t, []string{"Iterate/2/3.txt", "Iterate/3/4.txt"}, keys(IterateOptions{Recursive: true, StartAfter: "Iterate/2/"}))
+	assert.Equal(t, []string{"Iterate/1.txt", "Iterate/2.txt"}, keys(IterateOptions{MaxKeys: 2}))
+
+	for object, err := range driver.Iterate("Iterate", IterateOptions{}) {
+		assert.Nil(t, err)
+		assert.Equal(t, "Iterate/1.txt", object.Key)
+		assert.Equal(t, int64(7), object.Size)
+		assert.NotEmpty(t, object.ETag)
+		assert.Equal(t, "STANDARD", object.StorageClass)
+		assert.False(t, object.IsDir)
+		assert.False(t, object.LastModified.IsZero())
+		break
+	}
+
+	for i := 0; i < 2500; i++ {
+		fake.put(fmt.Sprintf("Iterate/4/%04d.txt", i), []byte("Goravel"))
+	}
+	count, requests := 0, fake.count("ListObjectsV2")
+	for _, err := range driver.Iterate("Iterate/4", IterateOptions{Recursive: true}) {
+		assert.Nil(t, err)
+		count++
+	}
+	assert.Equal(t, 2500, count)
+	assert.Equal(t, 3, fake.count("ListObjectsV2")-requests)
+
+	// A capped walk sends no request past the page holding the last entry.
+	count, requests = 0, fake.count("ListObjectsV2")
+	for _, err := range driver.Iterate("Iterate/4", IterateOptions{Recursive: true, MaxKeys: 10}) {
+		assert.Nil(t, err)
+		count++
+	}
+	assert.Equal(t, 10, count)
+	assert.Equal(t, 1, fake.count("ListObjectsV2")-requests)
+}
+
 type File struct {
 	path string
 }
@@ -458,3 +684,51 @@ func (f *File) Store(path string) (string, error) {
 func (f *File) StoreAs(path string, name string) (string, error) {
 	return "", nil
 }
+
+func TestWithContext(t *testing.T) {
+	driver, fake := newFakeDriver(t)
+	fake.put("WithContext/1.txt", []byte("Goravel"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	withContext := driver.WithContext(ctx).(*S3)
+	assert.NotSame(t, driver, withContext)
+	assert.Same(t, driver.instance, withContext.instance)
+	assert.Equal(t, ctx, withContext.ctx)
+	assert.Equal(t, context.Background(), driver.ctx)
+
+	cancel()
+	_, err := withContext.Check("WithContext/1.txt")
+	assert.ErrorIs(t, err, context.Canceled)
+	exists, err := driver.Check("WithContext/1.txt")
+	assert.Nil(t, err)
+	assert.True(t, exists)
+}
+
+// BenchmarkWithContext compares a request through a driver created by WithContext with one through a driver
+// created by NewS3, as WithContext used to do.
+func BenchmarkWithContext(b *testing.B) {
+	driver, fake := newFakeDriver(b)
+	fake.put("WithContext/1.txt", []byte("Goravel"))
+
+	b.Run("shared", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := driver.WithContext(context.Background()).(*S3).Check("WithContext/1.txt"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("new", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			newDriver, err := NewS3(context.Background(), driver.config, driver.disk)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := newDriver.Check("WithContext/1.txt"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -2,6 +2,8 @@ package s3
 
 import (
 	"context"
+	"net/http"
+	"sync"
 
 	"github.com/goravel/framework/contracts/binding"
 	"github.com/goravel/framework/contracts/foundation"
@@ -12,6 +14,11 @@ const Binding = "goravel.s3"
 var App foundation.Application
 
 type ServiceProvider struct {
+	// HTTPClient is the HTTP client of the drivers, it replaces the one built from filesystems.disks.<disk>.http.
+	HTTPClient *http.Client
+
+	// drivers caches the driver of each disk, so the client and its connections are shared by the callers.
+	drivers sync.Map
 }
 
 func (r *ServiceProvider) Relationship() binding.Relationship {
@@ -32,7 +39,28 @@ func (r *ServiceProvider) Register(app foundation.Application) {
 	App = app
 
 	app.BindWith(Binding, func(app foundation.Application, parameters map[string]any) (any, error) {
-		return NewS3(context.Background(), app.MakeConfig(), parameters["disk"].(string))
+		disk := parameters["disk"].(string)
+		if driver, ok := r.drivers.Load(disk); ok {
+			return driver, nil
+		}
+
+		var opts []Option
+		if logger := app.MakeLog(); logger != nil {
+			opts = append(opts, WithLogger(logger))
+		}
+		if r.HTTPClient != nil {
+			opts = append(opts, WithHTTPClient(r.HTTPClient))
+		}
+
+		driver, err := NewS3(context.Background(), app.MakeConfig(), disk, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		// Another caller may have created the driver of the disk meanwhile, the first one is kept.
+		cached, _ := r.drivers.LoadOrStore(disk, driver)
+
+		return cached, nil
 	})
 }
 
@@ -0,0 +1,33 @@
+package s3
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/goravel/framework/contracts/foundation"
+	mocksfoundation "github.com/goravel/framework/mocks/foundation"
+)
+
+func TestServiceProvider(t *testing.T) {
+	driver, _ := newFakeDriver(t)
+
+	var bind func(foundation.Application, map[string]any) (any, error)
+	mockApp := mocksfoundation.NewApplication(t)
+	mockApp.EXPECT().BindWith(Binding, mock.Anything).Run(func(_ any, callback func(foundation.Application, map[string]any) (any, error)) {
+		bind = callback
+	}).Once()
+	mockApp.EXPECT().MakeLog().Return(nil).Once()
+	mockApp.EXPECT().MakeConfig().Return(driver.config).Once()
+
+	serviceProvider := &ServiceProvider{}
+	serviceProvider.Register(mockApp)
+
+	// The driver of a disk is created once and shared.
+	first, err := bind(mockApp, map[string]any{"disk": "s3"})
+	assert.Nil(t, err)
+	second, err := bind(mockApp, map[string]any{"disk": "s3"})
+	assert.Nil(t, err)
+	assert.Same(t, first, second)
+}
@@ -0,0 +1,72 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+
+	"github.com/goravel/framework/contracts/config"
+)
+
+// loadSharedConfig loads the region, credentials, endpoint and retry settings of the disk from the shared config and
+// credentials files the way the AWS CLI does, when the profile or the files of the disk are set. Otherwise, the
+// configuration only holds the region of the disk and its credentials are nil. The region of the disk takes
+// precedence over the one of the profile. The HTTP client, if not nil, sends the requests of the configuration,
+// such as the ones of the profile credentials.
+func loadSharedConfig(ctx context.Context, config config.Config, disk, region string, httpClient aws.HTTPClient) (aws.Config, error) {
+	profile := config.GetString(fmt.Sprintf("filesystems.disks.%s.profile", disk))
+	configFiles := configStrings(config, fmt.Sprintf("filesystems.disks.%s.shared_config_files", disk))
+	credentialsFiles := configStrings(config, fmt.Sprintf("filesystems.disks.%s.shared_credentials_files", disk))
+	if profile == "" && len(configFiles) == 0 && len(credentialsFiles) == 0 {
+		return aws.Config{Region: region, HTTPClient: httpClient}, nil
+	}
+
+	var optFns []func(*awsconfig.LoadOptions) error
+	if httpClient != nil {
+		optFns = append(optFns, awsconfig.WithHTTPClient(httpClient))
+	}
+	if region != "" {
+		optFns = append(optFns, awsconfig.WithRegion(region))
+	}
+	if profile != "" {
+		optFns = append(optFns, awsconfig.WithSharedConfigProfile(profile))
+	}
+	if len(configFiles) > 0 {
+		optFns = append(optFns, awsconfig.WithSharedConfigFiles(configFiles))
+	}
+	if len(credentialsFiles) > 0 {
+		optFns = append(optFns, awsconfig.WithSharedCredentialsFiles(credentialsFiles))
+	}
+
+	awsConfig, err := awsconfig.LoadDefaultConfig(ctx, optFns...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to load the shared configuration of %s: %w", disk, err)
+	}
+
+	return awsConfig, nil
+}
+
+// configStrings reads a list of strings, a single string is read as a list of one.
+func configStrings(config config.Config, key string) []string {
+	switch value := config.Get(key).(type) {
+	case string:
+		if value != "" {
+			return []string{value}
+		}
+	case []string:
+		return value
+	case []any:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if item, ok := item.(string); ok && item != "" {
+				values = append(values, item)
+			}
+		}
+
+		return values
+	}
+
+	return nil
+}
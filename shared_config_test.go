@@ -0,0 +1,226 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeSharedConfig writes a shared config file with a staging profile and a shared credentials file with its keys.
+func writeSharedConfig(t *testing.T, endpoint string) (string, string) {
+	directory := t.TempDir()
+	configFile := filepath.Join(directory, "config")
+	credentialsFile := filepath.Join(directory, "credentials")
+
+	assert.Nil(t, os.WriteFile(configFile, []byte(fmt.Sprintf(`[default]
+region = us-east-1
+
+[profile staging]
+region = eu-west-1
+endpoint_url = %s
+max_attempts = 2
+`, endpoint)), 0600))
+	assert.Nil(t, os.WriteFile(credentialsFile, []byte(`[default]
+aws_access_key_id = default-key
+aws_secret_access_key = secret
+
+[staging]
+aws_access_key_id = staging-key
+aws_secret_access_key = secret
+`), 0600))
+
+	return configFile, credentialsFile
+}
+
+func TestSharedConfig(t *testing.T) {
+	isolateCredentials(t)
+	fake := newFakeS3(t)
+	configFile, credentialsFile := writeSharedConfig(t, fake.server.URL)
+
+	tests := []struct {
+		name string
+		// env is the AWS_PROFILE environment variable.
+		env       string
+		values    map[string]any
+		region    string
+		accessKey string
+	}{
+		{
+			name: "profile",
+			values: map[string]any{
+				"filesystems.disks.s3.profile":                  "staging",
+				"filesystems.disks.s3.shared_config_files":      []any{configFile},
+				"filesystems.disks.s3.shared_credentials_files": []string{credentialsFile},
+			},
+			region:    "eu-west-1",
+			accessKey: "staging-key",
+		},
+		{
+			name: "profile from the environment",
+			env:  "staging",
+			values: map[string]any{
+				"filesystems.disks.s3.shared_config_files":      configFile,
+				"filesystems.disks.s3.shared_credentials_files": credentialsFile,
+			},
+			region:    "eu-west-1",
+			accessKey: "staging-key",
+		},
+		{
+			name: "disk settings take precedence",
+			values: map[string]any{
+				"filesystems.disks.s3.key":                      "key",
+				"filesystems.disks.s3.secret":                   "secret",
+				"filesystems.disks.s3.region":                   "ap-southeast-1",
+				"filesystems.disks.s3.profile":                  "staging",
+				"filesystems.disks.s3.shared_config_files":      []string{configFile},
+				"filesystems.disks.s3.shared_credentials_files": []string{credentialsFile},
+			},
+			region:    "ap-southeast-1",
+			accessKey: "key",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("AWS_PROFILE", test.env)
+
+			values := map[string]any{
+				"app.timezone":                           "UTC",
+				"filesystems.disks.s3.bucket":            fake.bucket,
+				"filesystems.disks.s3.url":               "https://goravel.dev",
+				"filesystems.disks.s3.retry.max_backoff": 10 * time.Millisecond,
+			}
+			for key, value := range test.values {
+				values[key] = value
+			}
+
+			driver, err := NewS3(context.Background(), newMockConfig(t, values), "s3")
+			assert.Nil(t, err)
+			assert.Equal(t, test.region, driver.instance.Options().Region)
+
+			// The endpoint and the retry settings come from the profile.
+			fake.fail("PutObject", 1, http.StatusInternalServerError, "InternalError")
+			assert.Nil(t, driver.Put("SharedConfig.txt", "Goravel"))
+			assert.Equal(t, test.accessKey, fake.accessKey)
+
+			fake.fail("PutObject", 2, http.StatusInternalServerError, "InternalError")
+			assert.NotNil(t, driver.Put("SharedConfig.txt", "Goravel"))
+			fake.fail("PutObject", 0, 0, "")
+		})
+	}
+}
+
+func TestSharedConfig_MissingProfile(t *testing.T) {
+	isolateCredentials(t)
+	configFile, credentialsFile := writeSharedConfig(t, "http://127.0.0.1")
+
+	driver, err := NewS3(context.Background(), newMockConfig(t, map[string]any{
+		"filesystems.disks.s3.profile":                  "production",
+		"filesystems.disks.s3.shared_config_files":      []string{configFile},
+		"filesystems.disks.s3.shared_credentials_files": []string{credentialsFile},
+		"filesystems.disks.s3.bucket":                   "goravel",
+		"filesystems.disks.s3.url":                      "https://goravel.dev",
+	}), "s3")
+	assert.Nil(t, driver)
+	assert.ErrorContains(t, err, "failed to load the shared configuration of s3")
+}
+
+// redirectTransport sends the requests to the STS endpoints of AWS to a fake STS server.
+type redirectTransport struct {
+	sts      *url.URL
+	lock     sync.Mutex
+	requests []string
+}
+
+func (r *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.lock.Lock()
+	r.requests = append(r.requests, req.URL.Host)
+	r.lock.Unlock()
+
+	if strings.HasPrefix(req.URL.Host, "sts.") {
+		req = req.Clone(req.Context())
+		req.URL.Scheme, req.URL.Host = r.sts.Scheme, r.sts.Host
+	}
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestSharedConfig_HTTPClient(t *testing.T) {
+	fake := newFakeS3(t)
+	sts := newFakeSTS(t, time.Hour)
+	stsURL, err := url.Parse(sts.server.URL)
+	assert.Nil(t, err)
+
+	directory := t.TempDir()
+	configFile := filepath.Join(directory, "config")
+	credentialsFile := filepath.Join(directory, "credentials")
+	assert.Nil(t, os.WriteFile(configFile, []byte(`[profile assumer]
+region = eu-west-1
+role_arn = arn:aws:iam::123456789012:role/goravel
+source_profile = base
+`), 0600))
+	assert.Nil(t, os.WriteFile(credentialsFile, []byte(`[base]
+aws_access_key_id = base-key
+aws_secret_access_key = secret
+`), 0600))
+
+	tests := []struct {
+		name   string
+		values map[string]any
+	}{
+		{
+			name: "profile",
+			values: map[string]any{
+				"filesystems.disks.s3.profile":                  "assumer",
+				"filesystems.disks.s3.shared_config_files":      []string{configFile},
+				"filesystems.disks.s3.shared_credentials_files": []string{credentialsFile},
+			},
+		},
+		{
+			name: "default credentials",
+			values: map[string]any{
+				"filesystems.disks.s3.region": "eu-west-1",
+			},
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			isolateCredentials(t)
+			t.Setenv("AWS_CONFIG_FILE", configFile)
+			t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+			t.Setenv("AWS_PROFILE", "assumer")
+			transport := &redirectTransport{sts: stsURL}
+
+			values := map[string]any{
+				"app.timezone":                     "UTC",
+				"filesystems.disks.s3.bucket":      fake.bucket,
+				"filesystems.disks.s3.url":         "https://goravel.dev",
+				"filesystems.disks.s3.endpoint":    fake.server.URL,
+				"filesystems.disks.s3.http.client": &http.Client{Transport: transport},
+			}
+			for key, value := range test.values {
+				values[key] = value
+			}
+
+			driver, err := NewS3(context.Background(), newMockConfig(t, values), "s3")
+			assert.Nil(t, err)
+
+			// The role of the profile is assumed through the HTTP client of the disk.
+			assert.Nil(t, driver.Put("SharedConfig.txt", "Goravel"))
+			assert.Equal(t, fmt.Sprintf("assumed-key-%d", i+1), fake.accessKey)
+			assert.Len(t, sts.requests, i+1)
+			assert.Equal(t, "base-key", sts.accessKeys[i])
+			assert.Contains(t, transport.requests, "sts.eu-west-1.amazonaws.com")
+		})
+	}
+}
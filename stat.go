@@ -0,0 +1,155 @@
+package s3
+
+import (
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Stat gets all the attributes of a file with one HeadObject, the last modified time is in the app.timezone.
+// The checksum is only requested when filesystems.disks.<disk>.stat.checksum is true, since S3 then requires
+// kms:Decrypt for the SSE-KMS objects. When filesystems.disks.<disk>.stat.cache_ttl is set, the result is cached
+// for that duration. The cache is cleared by the writes of the driver, but not by the writes of other processes.
+func (r *S3) Stat(file string) (ObjectInfo, error) {
+	if info, ok := r.stats.get(file); ok {
+		return info, nil
+	}
+
+	location, err := r.location()
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	resp, err := r.headObject(file, func(input *s3.HeadObjectInput) {
+		if r.statChecksum {
+			input.ChecksumMode = types.ChecksumModeEnabled
+		}
+	})
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	info := ObjectInfo{
+		Key:                  file,
+		Size:                 aws.ToInt64(resp.ContentLength),
+		ETag:                 aws.ToString(resp.ETag),
+		LastModified:         aws.ToTime(resp.LastModified).In(location),
+		StorageClass:         string(resp.StorageClass),
+		ContentType:          aws.ToString(resp.ContentType),
+		VersionID:            aws.ToString(resp.VersionId),
+		Metadata:             cloneMetadata(resp.Metadata),
+		ServerSideEncryption: string(resp.ServerSideEncryption),
+		SSEKMSKeyID:          aws.ToString(resp.SSEKMSKeyId),
+	}
+	// HeadObject omits the storage class of the STANDARD objects, the listings don't.
+	if info.StorageClass == "" {
+		info.StorageClass = string(types.StorageClassStandard)
+	}
+	for _, checksum := range []struct {
+		algorithm types.ChecksumAlgorithm
+		value     *string
+	}{
+		{types.ChecksumAlgorithmCrc32, resp.ChecksumCRC32},
+		{types.ChecksumAlgorithmCrc32c, resp.ChecksumCRC32C},
+		{types.ChecksumAlgorithmCrc64nvme, resp.ChecksumCRC64NVME},
+		{types.ChecksumAlgorithmSha1, resp.ChecksumSHA1},
+		{types.ChecksumAlgorithmSha256, resp.ChecksumSHA256},
+	} {
+		if checksum.value != nil {
+			info.ChecksumAlgorithm, info.Checksum = string(checksum.algorithm), *checksum.value
+			break
+		}
+	}
+
+	r.stats.set(file, info)
+
+	return info, nil
+}
+
+// statCache caches the results of Stat, a nil cache caches nothing. It's shared by the copies of the driver
+// returned by WithContext.
+type statCache struct {
+	entries   map[string]statEntry
+	lastSweep time.Time
+	lock      sync.Mutex
+	ttl       time.Duration
+}
+
+type statEntry struct {
+	expiresAt time.Time
+	info      ObjectInfo
+}
+
+// newStatCache returns nil when the ttl isn't positive.
+func newStatCache(ttl time.Duration) *statCache {
+	if ttl <= 0 {
+		return nil
+	}
+
+	return &statCache{
+		entries:   make(map[string]statEntry),
+		lastSweep: time.Now(),
+		ttl:       ttl,
+	}
+}
+
+func (r *statCache) get(file string) (ObjectInfo, bool) {
+	if r == nil {
+		return ObjectInfo{}, false
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	entry, ok := r.entries[file]
+	if !ok || !time.Now().Before(entry.expiresAt) {
+		return ObjectInfo{}, false
+	}
+
+	// The metadata is cloned, so the caller can't change the cached entry.
+	info := entry.info
+	info.Metadata = maps.Clone(entry.info.Metadata)
+
+	return info, true
+}
+
+// set caches the info, the expired entries are removed once per ttl to keep the cache bounded.
+func (r *statCache) set(file string, info ObjectInfo) {
+	if r == nil {
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := time.Now()
+	if now.Sub(r.lastSweep) >= r.ttl {
+		for key, entry := range r.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(r.entries, key)
+			}
+		}
+		r.lastSweep = now
+	}
+
+	info.Metadata = maps.Clone(info.Metadata)
+	r.entries[file] = statEntry{expiresAt: now.Add(r.ttl), info: info}
+}
+
+// forget removes the files from the cache, it's called after they are written or deleted.
+func (r *statCache) forget(files ...string) {
+	if r == nil {
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, file := range files {
+		delete(r.entries, file)
+	}
+}
@@ -0,0 +1,171 @@
+package s3
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStat(t *testing.T) {
+	driver, fake := newFakeDriver(t, map[string]any{
+		"app.timezone": "Asia/Shanghai",
+	})
+
+	assert.Nil(t, driver.PutWithOptions("Stat/1.csv", "a,b",
+		WithContentType("text/csv"),
+		WithMetadata(map[string]string{"uploader": "1"}),
+	))
+	object := fake.objects["Stat/1.csv"]
+	object.headers.Set("X-Amz-Version-Id", "v1")
+	object.headers.Set("X-Amz-Server-Side-Encryption", "aws:kms")
+	object.headers.Set("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id", "key-1")
+	object.headers.Set("X-Amz-Checksum-Sha256", "47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=")
+	// The checksum isn't requested by default, so kms:Decrypt isn't required.
+	fake.kmsDenied = true
+	requests := fake.count("HeadObject")
+
+	info, err := driver.Stat("Stat/1.csv")
+	assert.Nil(t, err)
+	location, err := time.LoadLocation("Asia/Shanghai")
+	assert.Nil(t, err)
+	assert.Equal(t, ObjectInfo{
+		Key:                  "Stat/1.csv",
+		Size:                 3,
+		ETag:                 object.etag,
+		LastModified:         object.lastModified.In(location),
+		StorageClass:         "STANDARD",
+		ContentType:          "text/csv",
+		VersionID:            "v1",
+		Metadata:             map[string]string{"uploader": "1"},
+		ServerSideEncryption: "aws:kms",
+		SSEKMSKeyID:          "key-1",
+	}, info)
+	assert.Equal(t, location, info.LastModified.Location())
+	assert.Equal(t, requests+1, fake.count("HeadObject"))
+
+	// The cache is disabled by default.
+	_, err = driver.Stat("Stat/1.csv")
+	assert.Nil(t, err)
+	assert.Equal(t, requests+2, fake.count("HeadObject"))
+
+	assert.Nil(t, driver.PutWithOptions("Stat/2.txt", "Goravel", WithStorageClass("STANDARD_IA")))
+	info, err = driver.Stat("Stat/2.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "STANDARD_IA", info.StorageClass)
+	assert.Empty(t, info.Metadata)
+	assert.Empty(t, info.Checksum)
+
+	_, err = driver.Stat("Stat/3.txt")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+}
+
+func TestStat_Checksum(t *testing.T) {
+	driver, fake := newFakeDriver(t, map[string]any{
+		"filesystems.disks.s3.stat.checksum": true,
+	})
+
+	assert.Nil(t, driver.Put("Stat/1.txt", "Goravel"))
+	fake.objects["Stat/1.txt"].headers.Set("X-Amz-Checksum-Crc32", "3mzpIg==")
+	info, err := driver.Stat("Stat/1.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "CRC32", info.ChecksumAlgorithm)
+	assert.Equal(t, "3mzpIg==", info.Checksum)
+
+	// The checksum of an SSE-KMS object requires kms:Decrypt.
+	assert.Nil(t, driver.Put("Stat/2.txt", "Goravel"))
+	fake.objects["Stat/2.txt"].headers.Set("X-Amz-Server-Side-Encryption", "aws:kms")
+	fake.kmsDenied = true
+	_, err = driver.Stat("Stat/2.txt")
+	assert.ErrorIs(t, err, ErrAccessDenied)
+}
+
+func TestStat_Cache(t *testing.T) {
+	driver, fake := newFakeDriver(t, multipartConfigValues(), map[string]any{
+		"filesystems.disks.s3.stat.cache_ttl": 50 * time.Millisecond,
+	})
+
+	assert.Nil(t, driver.Put("Stat/1.txt", "Goravel"))
+	requests := fake.count("HeadObject")
+
+	info, err := driver.Stat("Stat/1.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(7), info.Size)
+	info.Metadata["uploader"] = "1"
+
+	// The copies of the driver share the cache.
+	cached, err := driver.WithContext(t.Context()).(*S3).Stat("Stat/1.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(7), cached.Size)
+	assert.Empty(t, cached.Metadata)
+	assert.Equal(t, requests+1, fake.count("HeadObject"))
+
+	// The entry expires after the ttl.
+	time.Sleep(60 * time.Millisecond)
+	_, err = driver.Stat("Stat/1.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, requests+2, fake.count("HeadObject"))
+
+	// The writes of the driver clear the entries.
+	tests := []struct {
+		name  string
+		write func() error
+		size  int64
+	}{
+		{
+			name:  "Put",
+			write: func() error { return driver.Put("Stat/1.txt", "Goravel 2") },
+			size:  9,
+		},
+		{
+			name: "multipart upload",
+			write: func() error {
+				upload, err := driver.CreateUpload("Stat/1.txt")
+				if err != nil {
+					return err
+				}
+				if err := driver.UploadPart(upload, 1, strings.NewReader("Goravel 3!")); err != nil {
+					return err
+				}
+
+				return driver.CompleteUpload(upload)
+			},
+			size: 10,
+		},
+		{
+			name: "Copy",
+			write: func() error {
+				if err := driver.Put("Stat/2.txt", "Goravel"); err != nil {
+					return err
+				}
+
+				return driver.Copy("Stat/2.txt", "Stat/1.txt")
+			},
+			size: 7,
+		},
+		{
+			name:  "UpdateMetadata",
+			write: func() error { return driver.UpdateMetadata("Stat/1.txt", map[string]string{"uploader": "1"}) },
+			size:  7,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := driver.Stat("Stat/1.txt")
+			assert.Nil(t, err)
+
+			assert.Nil(t, test.write())
+			info, err := driver.Stat("Stat/1.txt")
+			assert.Nil(t, err)
+			assert.Equal(t, test.size, info.Size)
+		})
+	}
+	info, err = driver.Stat("Stat/1.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"uploader": "1"}, info.Metadata)
+
+	assert.Nil(t, driver.Delete("Stat/1.txt"))
+	_, err = driver.Stat("Stat/1.txt")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+}
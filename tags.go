@@ -0,0 +1,75 @@
+package s3
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// WithTags sets the tags of the object, e.g. to match the filters of lifecycle rules or for cost allocation.
+func WithTags(tags map[string]string) PutOption {
+	return func(o *putOptions) {
+		o.tags = tags
+	}
+}
+
+// DeleteTags removes all the tags of a file.
+func (r *S3) DeleteTags(file string) error {
+	if _, err := r.instance.DeleteObjectTagging(r.ctx, &s3.DeleteObjectTaggingInput{
+		Bucket: aws.String(r.bucket),
+		Key:    aws.String(file),
+	}); err != nil {
+		return r.wrapError(file, err)
+	}
+
+	return nil
+}
+
+// SetTags replaces the tags of a file, the tags that aren't given are removed.
+func (r *S3) SetTags(file string, tags map[string]string) error {
+	tagSet := make([]types.Tag, 0, len(tags))
+	for key, value := range tags {
+		tagSet = append(tagSet, types.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+
+	if _, err := r.instance.PutObjectTagging(r.ctx, &s3.PutObjectTaggingInput{
+		Bucket:  aws.String(r.bucket),
+		Key:     aws.String(file),
+		Tagging: &types.Tagging{TagSet: tagSet},
+	}); err != nil {
+		return r.wrapError(file, err)
+	}
+
+	return nil
+}
+
+// Tags gets the tags of a file.
+func (r *S3) Tags(file string) (map[string]string, error) {
+	resp, err := r.instance.GetObjectTagging(r.ctx, &s3.GetObjectTaggingInput{
+		Bucket: aws.String(r.bucket),
+		Key:    aws.String(file),
+	})
+	if err != nil {
+		return nil, r.wrapError(file, err)
+	}
+
+	tags := make(map[string]string, len(resp.TagSet))
+	for _, tag := range resp.TagSet {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+
+	return tags, nil
+}
+
+// encodeTags encodes the tags as the URL query of the x-amz-tagging header, S3 doesn't decode a space from a plus.
+func encodeTags(tags map[string]string) string {
+	values := make(url.Values, len(tags))
+	for key, value := range tags {
+		values.Set(key, value)
+	}
+
+	return strings.ReplaceAll(values.Encode(), "+", "%20")
+}
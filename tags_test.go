@@ -0,0 +1,72 @@
+package s3
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTags(t *testing.T) {
+	driver, fake := newFakeDriver(t)
+
+	assert.Nil(t, driver.Put("Tags/1.txt", "Goravel"))
+	puts := fake.count("PutObject")
+	tags, err := driver.Tags("Tags/1.txt")
+	assert.Nil(t, err)
+	assert.Empty(t, tags)
+
+	assert.Nil(t, driver.SetTags("Tags/1.txt", map[string]string{"retention": "30d", "team": "web & api"}))
+	tags, err = driver.Tags("Tags/1.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"retention": "30d", "team": "web & api"}, tags)
+
+	// The tags are replaced as a whole.
+	assert.Nil(t, driver.SetTags("Tags/1.txt", map[string]string{"retention": "90d"}))
+	tags, err = driver.Tags("Tags/1.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"retention": "90d"}, tags)
+	// The tags are set without rewriting the object.
+	assert.Equal(t, puts, fake.count("PutObject"))
+
+	// A copy keeps the tags of the origin.
+	assert.Nil(t, driver.Copy("Tags/1.txt", "Tags/2.txt"))
+	tags, err = driver.Tags("Tags/2.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"retention": "90d"}, tags)
+
+	assert.Nil(t, driver.DeleteTags("Tags/1.txt"))
+	tags, err = driver.Tags("Tags/1.txt")
+	assert.Nil(t, err)
+	assert.Empty(t, tags)
+	assert.True(t, driver.Exists("Tags/1.txt"))
+
+	_, err = driver.Tags("Tags/3.txt")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+	assert.ErrorIs(t, driver.SetTags("Tags/3.txt", map[string]string{"retention": "30d"}), ErrObjectNotFound)
+	assert.ErrorIs(t, driver.DeleteTags("Tags/3.txt"), ErrObjectNotFound)
+}
+
+func TestTags_Put(t *testing.T) {
+	driver, fake := newFakeDriver(t, multipartConfigValues())
+	tags := map[string]string{"retention": "30d", "cost center": "a+b/c"}
+	expected := url.Values{"retention": {"30d"}, "cost center": {"a+b/c"}}
+
+	assert.Nil(t, driver.PutWithOptions("Tags/1.txt", "Goravel", WithTags(tags)))
+	assert.Equal(t, expected, fake.objects["Tags/1.txt"].tags)
+
+	assert.Nil(t, driver.PutStream("Tags/2.txt", bytes.NewReader(bytes.Repeat([]byte("a"), 11*1024*1024)), WithTags(tags)))
+	assert.Equal(t, expected, fake.objects["Tags/2.txt"].tags)
+
+	upload, err := driver.CreateUpload("Tags/3.txt", WithTags(tags))
+	assert.Nil(t, err)
+	assert.Nil(t, driver.UploadPart(upload, 1, strings.NewReader("Goravel")))
+	assert.Nil(t, driver.CompleteUpload(upload))
+	assert.Equal(t, expected, fake.objects["Tags/3.txt"].tags)
+
+	got, err := driver.Tags("Tags/3.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, tags, got)
+}
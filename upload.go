@@ -0,0 +1,351 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/gabriel-vasile/mimetype"
+)
+
+const (
+	// mimeSniffLength is the number of leading bytes used to detect the MIME type.
+	mimeSniffLength = 3 * 1024
+	// minPartSize is the smallest part size S3 accepts for a multipart upload, except for the last part.
+	minPartSize = 5 * 1024 * 1024
+	// maxParts is the maximum number of parts of a multipart upload.
+	maxParts = 10000
+
+	defaultMultipartThreshold   = 16 * 1024 * 1024
+	defaultMultipartPartSize    = 8 * 1024 * 1024
+	defaultMultipartConcurrency = 4
+	defaultMultipartMaxRetries  = 3
+)
+
+// multipartConfig is read from filesystems.disks.<disk>.multipart.
+type multipartConfig struct {
+	// threshold is the size from which a content of known size is uploaded with a multipart upload.
+	threshold   int64
+	partSize    int64
+	concurrency int
+	// maxRetries is the number of times a failed part is uploaded again.
+	maxRetries int
+}
+
+// contentPart is a numbered part of the content to upload.
+type contentPart struct {
+	number int32
+	body   io.ReadSeeker
+	size   int64
+}
+
+// makeParentDirectories creates the folders of the file, if the file is created in a folder directly,
+// we can't check if the folder exists.
+func (r *S3) makeParentDirectories(file string) error {
+	if strings.HasSuffix(file, "/") {
+		return nil
+	}
+
+	folders := strings.Split(file, "/")
+	for i := 1; i < len(folders); i++ {
+		folder := strings.Join(folders[:i], "/")
+		if err := r.MakeDirectory(folder); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// putSeeker uploads a seekable content from its current offset, the content is sent in a single request
+// below the multipart threshold. A content that can't seek, such as an *os.File of a pipe, is uploaded
+// like a reader.
+func (r *S3) putSeeker(file string, seeker io.ReadSeeker, options putOptions) error {
+	offset, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return r.putReader(file, seeker, options)
+	}
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return r.putReader(file, seeker, options)
+	}
+	if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	size := end - offset
+	if options.contentType == "" {
+		head := make([]byte, min(size, mimeSniffLength))
+		if _, err := io.ReadFull(seeker, head); err != nil {
+			return err
+		}
+		if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+			return err
+		}
+		options.contentType = mimetype.Detect(head).String()
+	}
+
+	// S3 rejects a multipart upload without parts, so an empty content is always sent in a single request.
+	if size == 0 || size < r.multipart.threshold {
+		return r.putObject(file, seeker, size, options)
+	}
+
+	// The parts can be read concurrently without buffering them when the content supports ReadAt.
+	if readerAt, ok := seeker.(io.ReaderAt); ok {
+		return r.putMultipart(file, options, sectionParts(io.NewSectionReader(readerAt, offset, size), size, r.partSize(size)))
+	}
+
+	return r.putMultipart(file, options, bufferParts(seeker, nil, r.partSize(size)))
+}
+
+// putReader uploads a content of unknown length, the content is sent in a single request if it fits in
+// one part, otherwise it is sent through a multipart upload.
+func (r *S3) putReader(file string, reader io.Reader, options putOptions) error {
+	buffer := make([]byte, r.multipart.partSize)
+	n, err := io.ReadFull(reader, buffer)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		if options.contentType == "" {
+			options.contentType = mimetype.Detect(buffer[:n]).String()
+		}
+
+		return r.putObject(file, bytes.NewReader(buffer[:n]), int64(n), options)
+	}
+	if err != nil {
+		return err
+	}
+
+	if options.contentType == "" {
+		options.contentType = mimetype.Detect(buffer).String()
+	}
+
+	return r.putMultipart(file, options, bufferParts(reader, buffer, r.multipart.partSize))
+}
+
+func (r *S3) putObject(file string, body io.Reader, size int64, options putOptions) error {
+	putObjectInput := r.putObjectInput(file, options)
+	putObjectInput.Body = body
+	putObjectInput.ContentLength = aws.Int64(size)
+
+	_, err := r.instance.PutObject(r.ctx, putObjectInput)
+	r.stats.forget(file)
+
+	return err
+}
+
+// putMultipart uploads the parts concurrently through a multipart upload, the upload is aborted if
+// any part can't be uploaded.
+func (r *S3) putMultipart(file string, options putOptions, parts iter.Seq2[contentPart, error]) error {
+	upload, err := r.instance.CreateMultipartUpload(r.ctx, r.createMultipartUploadInput(file, options))
+	if err != nil {
+		return err
+	}
+
+	uploadedParts, err := r.putParts(file, upload.UploadId, parts)
+	if err != nil {
+		return r.abortMultipartUpload(file, upload.UploadId, err)
+	}
+
+	if err := r.completeMultipartUpload(file, upload.UploadId, uploadedParts); err != nil {
+		return r.abortMultipartUpload(file, upload.UploadId, err)
+	}
+
+	return nil
+}
+
+// putParts uploads the parts with at most multipart.concurrency requests in flight. The uploaded parts
+// are returned in part number order, including when an error occurs.
+func (r *S3) putParts(file string, uploadId *string, parts iter.Seq2[contentPart, error]) ([]UploadedPart, error) {
+	ctx, cancel := context.WithCancel(r.ctx)
+	defer cancel()
+
+	var (
+		uploadedParts []UploadedPart
+		errs          []error
+		lock          sync.Mutex
+		wg            sync.WaitGroup
+	)
+	failed := func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+
+		return len(errs) > 0
+	}
+
+	semaphore := make(chan struct{}, r.multipart.concurrency)
+	for part, err := range parts {
+		if err != nil {
+			lock.Lock()
+			errs = append(errs, err)
+			lock.Unlock()
+			break
+		}
+
+		semaphore <- struct{}{}
+		if failed() {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			etag, err := r.putPart(ctx, file, uploadId, part)
+
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				cancel()
+				return
+			}
+
+			uploadedParts = append(uploadedParts, UploadedPart{
+				PartNumber: part.number,
+				ETag:       aws.ToString(etag),
+				Size:       part.size,
+			})
+		}()
+	}
+	wg.Wait()
+
+	sortParts(uploadedParts)
+
+	return uploadedParts, errors.Join(errs...)
+}
+
+// putPart uploads a part, retrying it up to multipart.max_retries times with a jittered backoff.
+func (r *S3) putPart(ctx context.Context, file string, uploadId *string, part contentPart) (*string, error) {
+	var err error
+	for attempt := 0; attempt <= r.multipart.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, err
+			case <-time.After(backoff(attempt)):
+			}
+
+			if _, err := part.body.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+		}
+
+		var uploadPartResponse *s3.UploadPartOutput
+		uploadPartResponse, err = r.instance.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:        aws.String(r.bucket),
+			Key:           aws.String(file),
+			UploadId:      uploadId,
+			PartNumber:    aws.Int32(part.number),
+			Body:          part.body,
+			ContentLength: aws.Int64(part.size),
+		})
+		if err == nil {
+			return uploadPartResponse.ETag, nil
+		}
+	}
+
+	return nil, err
+}
+
+func (r *S3) completeMultipartUpload(file string, uploadId *string, uploadedParts []UploadedPart) error {
+	completedParts := make([]types.CompletedPart, 0, len(uploadedParts))
+	for _, uploadedPart := range uploadedParts {
+		completedParts = append(completedParts, types.CompletedPart{
+			ETag:       aws.String(uploadedPart.ETag),
+			PartNumber: aws.Int32(uploadedPart.PartNumber),
+		})
+	}
+
+	_, err := r.instance.CompleteMultipartUpload(r.ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:   aws.String(r.bucket),
+		Key:      aws.String(file),
+		UploadId: uploadId,
+		MultipartUpload: &types.CompletedMultipartUpload{
+			Parts: completedParts,
+		},
+	})
+	r.stats.forget(file)
+
+	return err
+}
+
+// abortMultipartUpload aborts the upload to release the uploaded parts, and returns the error that caused it.
+func (r *S3) abortMultipartUpload(file string, uploadId *string, cause error) error {
+	// The upload should be aborted even if the context is canceled.
+	_, err := r.instance.AbortMultipartUpload(context.WithoutCancel(r.ctx), &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(r.bucket),
+		Key:      aws.String(file),
+		UploadId: uploadId,
+	})
+
+	return errors.Join(cause, err)
+}
+
+// partSize returns the configured part size, raised if needed to upload the size within the part limit.
+func (r *S3) partSize(size int64) int64 {
+	return max(r.multipart.partSize, (size+maxParts-1)/maxParts)
+}
+
+// sectionParts splits the content into parts read through ReadAt, numbered from 1.
+func sectionParts(readerAt io.ReaderAt, size, partSize int64) iter.Seq2[contentPart, error] {
+	return func(yield func(contentPart, error) bool) {
+		number := int32(1)
+		for offset := int64(0); offset < size; offset += partSize {
+			partSize := min(partSize, size-offset)
+			if !yield(contentPart{number: number, body: io.NewSectionReader(readerAt, offset, partSize), size: partSize}, nil) {
+				return
+			}
+			number++
+		}
+	}
+}
+
+// bufferParts reads the content part by part in memory, starting with the given part if it's not nil.
+// The parts are numbered from 1.
+func bufferParts(reader io.Reader, first []byte, partSize int64) iter.Seq2[contentPart, error] {
+	return func(yield func(contentPart, error) bool) {
+		number := int32(1)
+		if first != nil {
+			if !yield(contentPart{number: number, body: bytes.NewReader(first), size: int64(len(first))}, nil) {
+				return
+			}
+			number++
+		}
+
+		for {
+			buffer := make([]byte, partSize)
+			n, err := io.ReadFull(reader, buffer)
+			if n > 0 {
+				if !yield(contentPart{number: number, body: bytes.NewReader(buffer[:n]), size: int64(n)}, nil) {
+					return
+				}
+				number++
+			}
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return
+			}
+			if err != nil {
+				yield(contentPart{}, err)
+				return
+			}
+		}
+	}
+}
+
+// backoff returns the jittered delay before the given retry attempt.
+func backoff(attempt int) time.Duration {
+	delay := min(100*time.Millisecond<<(attempt-1), 5*time.Second)
+
+	return delay/2 + rand.N(delay/2+1)
+}
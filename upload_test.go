@@ -0,0 +1,194 @@
+package s3
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errRead = errors.New("read error")
+
+// onlyReader hides the other interfaces of a reader, so it can't be seeked.
+type onlyReader struct {
+	io.Reader
+}
+
+// onlyReadSeeker hides the other interfaces of a reader, so it can't be read at an offset.
+type onlyReadSeeker struct {
+	io.ReadSeeker
+}
+
+func multipartConfigValues() map[string]any {
+	return map[string]any{
+		"filesystems.disks.s3.multipart.threshold": 10 * 1024 * 1024,
+		"filesystems.disks.s3.multipart.part_size": minPartSize,
+	}
+}
+
+func TestPutStream(t *testing.T) {
+	png, err := os.ReadFile("logo.png")
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name   string
+		reader func(content []byte) io.Reader
+		size   int
+		parts  int
+	}{
+		{
+			name: "small seekable",
+			reader: func(content []byte) io.Reader {
+				return bytes.NewReader(content)
+			},
+			size: 1024 * 1024,
+		},
+		{
+			name: "large seekable",
+			reader: func(content []byte) io.Reader {
+				return bytes.NewReader(content)
+			},
+			size:  12 * 1024 * 1024,
+			parts: 3,
+		},
+		{
+			name: "large seekable without ReadAt",
+			reader: func(content []byte) io.Reader {
+				return onlyReadSeeker{bytes.NewReader(content)}
+			},
+			size:  12 * 1024 * 1024,
+			parts: 3,
+		},
+		{
+			name: "small unseekable",
+			reader: func(content []byte) io.Reader {
+				return onlyReader{bytes.NewReader(content)}
+			},
+			size: 1024,
+		},
+		{
+			name: "large unseekable",
+			reader: func(content []byte) io.Reader {
+				return onlyReader{bytes.NewReader(content)}
+			},
+			size:  12 * 1024 * 1024,
+			parts: 3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			driver, fake := newFakeDriver(t, multipartConfigValues())
+			content := make([]byte, test.size)
+			copy(content, png)
+
+			assert.Nil(t, driver.PutStream("PutStream/1.png", test.reader(content)))
+			assert.Equal(t, []string{"PutStream/", "PutStream/1.png"}, fake.keys())
+			assert.Equal(t, content, fake.objects["PutStream/1.png"].data)
+			mimeType, err := driver.MimeType("PutStream/1.png")
+			assert.Nil(t, err)
+			assert.Equal(t, "image/png", mimeType)
+			if test.parts > 0 {
+				assert.Equal(t, 1, fake.count("CreateMultipartUpload"))
+				assert.Equal(t, test.parts, fake.count("UploadPart"))
+				assert.Equal(t, 1, fake.count("CompleteMultipartUpload"))
+			} else {
+				assert.Equal(t, 0, fake.count("CreateMultipartUpload"))
+			}
+		})
+	}
+}
+
+func TestPutStream_Retry(t *testing.T) {
+	driver, fake := newFakeDriver(t, multipartConfigValues())
+	fake.fail("UploadPart", 2, http.StatusBadRequest, "BadDigest")
+	content := make([]byte, 12*1024*1024)
+
+	assert.Nil(t, driver.PutStream("PutStream.txt", bytes.NewReader(content)))
+	assert.Equal(t, 5, fake.count("UploadPart"))
+	assert.Equal(t, content, fake.objects["PutStream.txt"].data)
+}
+
+func TestPutStream_Pipe(t *testing.T) {
+	driver, fake := newFakeDriver(t, multipartConfigValues())
+
+	for _, size := range []int{7, 12 * 1024 * 1024} {
+		// An *os.File of a pipe is an io.ReadSeeker, but its Seek fails.
+		reader, writer, err := os.Pipe()
+		assert.Nil(t, err)
+		content := bytes.Repeat([]byte("a"), size)
+		go func() {
+			_, _ = writer.Write(content)
+			_ = writer.Close()
+		}()
+
+		assert.Nil(t, driver.PutStream("PutStream.txt", reader))
+		assert.Nil(t, reader.Close())
+		assert.Equal(t, content, fake.objects["PutStream.txt"].data)
+	}
+	assert.Equal(t, 1, fake.count("CompleteMultipartUpload"))
+}
+
+func TestPutStream_Empty(t *testing.T) {
+	driver, fake := newFakeDriver(t, map[string]any{
+		"filesystems.disks.s3.multipart.threshold": 0,
+	})
+
+	// The empty contents, such as the parent directories, are sent in a single request even without threshold.
+	assert.Nil(t, driver.Put("PutStream/1.txt", ""))
+	assert.Nil(t, driver.MakeDirectory("PutStream/2"))
+	assert.ElementsMatch(t, []string{"PutStream/", "PutStream/1.txt", "PutStream/2/"}, fake.keys())
+	assert.Equal(t, 0, fake.count("CreateMultipartUpload"))
+
+	assert.Nil(t, driver.Put("PutStream/3.txt", "Goravel"))
+	assert.Equal(t, 1, fake.count("CompleteMultipartUpload"))
+}
+
+func TestPutStream_Abort(t *testing.T) {
+	t.Run("read error", func(t *testing.T) {
+		driver, fake := newFakeDriver(t, multipartConfigValues())
+		content := make([]byte, 7*1024*1024)
+
+		assert.ErrorIs(t, driver.PutStream("PutStream.txt", onlyReader{io.MultiReader(bytes.NewReader(content), iotest.ErrReader(errRead))}), errRead)
+		assert.Equal(t, 1, fake.count("AbortMultipartUpload"))
+		assert.Empty(t, fake.uploads)
+		assert.Empty(t, fake.keys())
+	})
+
+	t.Run("part error", func(t *testing.T) {
+		values := multipartConfigValues()
+		values["filesystems.disks.s3.multipart.max_retries"] = 1
+		driver, fake := newFakeDriver(t, values)
+		fake.fail("UploadPart", -1, http.StatusBadRequest, "BadDigest")
+
+		assert.NotNil(t, driver.PutStream("PutStream.txt", bytes.NewReader(make([]byte, 12*1024*1024))))
+		assert.Equal(t, 1, fake.count("AbortMultipartUpload"))
+		assert.Equal(t, 0, fake.count("CompleteMultipartUpload"))
+		assert.Empty(t, fake.uploads)
+		assert.Empty(t, fake.keys())
+	})
+}
+
+func TestPutFileAs_Stream(t *testing.T) {
+	driver, fake := newFakeDriver(t)
+
+	path, err := driver.PutFileAs("PutFileAs", &File{path: "logo.png"}, "logo")
+	assert.Nil(t, err)
+	assert.Equal(t, "PutFileAs/logo.png", path)
+	png, err := os.ReadFile("logo.png")
+	assert.Nil(t, err)
+	assert.Equal(t, png, fake.objects[path].data)
+	assert.Equal(t, "image/png", fake.objects[path].contentType)
+}
+
+func TestPartSize(t *testing.T) {
+	driver, _ := newFakeDriver(t)
+
+	assert.Equal(t, int64(defaultMultipartPartSize), driver.partSize(1024*1024*1024))
+	assert.Equal(t, int64(10737419), driver.partSize(100*1024*1024*1024))
+}
@@ -2,9 +2,13 @@ package s3
 
 import (
 	"fmt"
+	"net/url"
 	"path"
 	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/goravel/framework/contracts/filesystem"
 	"github.com/goravel/framework/support/file"
 )
@@ -24,6 +28,47 @@ func fullPathOfFile(filePath string, source filesystem.File, name string) (strin
 	}
 }
 
+// copySource returns the copy source of a file of the bucket, every segment of the key is escaped since S3 decodes
+// it, including a plus as a space.
+func (r *S3) copySource(file string) string {
+	segments := strings.Split(file, "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(url.QueryEscape(segment), "+", "%20")
+	}
+
+	return r.bucket + "/" + strings.Join(segments, "/")
+}
+
+// mergeListing merges the objects and the common prefixes of a listing page in key order.
+func mergeListing(output *s3.ListObjectsV2Output, location *time.Location) []ObjectInfo {
+	objects := make([]ObjectInfo, 0, len(output.Contents)+len(output.CommonPrefixes))
+	i, j := 0, 0
+	for i < len(output.Contents) || j < len(output.CommonPrefixes) {
+		if j == len(output.CommonPrefixes) ||
+			(i < len(output.Contents) && aws.ToString(output.Contents[i].Key) < aws.ToString(output.CommonPrefixes[j].Prefix)) {
+			object := output.Contents[i]
+			key := aws.ToString(object.Key)
+			objects = append(objects, ObjectInfo{
+				Key:          key,
+				Size:         aws.ToInt64(object.Size),
+				ETag:         aws.ToString(object.ETag),
+				LastModified: aws.ToTime(object.LastModified).In(location),
+				StorageClass: string(object.StorageClass),
+				IsDir:        strings.HasSuffix(key, "/"),
+			})
+			i++
+		} else {
+			objects = append(objects, ObjectInfo{
+				Key:   aws.ToString(output.CommonPrefixes[j].Prefix),
+				IsDir: true,
+			})
+			j++
+		}
+	}
+
+	return objects
+}
+
 func validPath(path string) string {
 	realPath := strings.TrimPrefix(path, "./")
 	realPath = strings.TrimPrefix(realPath, "/")
@@ -0,0 +1,89 @@
+package s3
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const (
+	VisibilityPublic  = "public"
+	VisibilityPrivate = "private"
+
+	defaultPublicACL  = string(types.ObjectCannedACLPublicRead)
+	defaultPrivateACL = string(types.ObjectCannedACLPrivate)
+
+	allUsersGroup           = "http://acs.amazonaws.com/groups/global/AllUsers"
+	authenticatedUsersGroup = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+)
+
+// visibilityConfig maps the visibilities to the canned ACLs set by filesystems.disks.<disk>.visibility.
+type visibilityConfig struct {
+	public  string
+	private string
+}
+
+// WithVisibility sets the visibility of the object, public or private, instead of the object_canned_acl of the disk.
+func WithVisibility(visibility string) PutOption {
+	return func(o *putOptions) {
+		o.visibility = visibility
+	}
+}
+
+// SetVisibility sets the visibility of a file, public or private, by applying the canned ACL it's mapped to.
+func (r *S3) SetVisibility(file, visibility string) error {
+	acl, err := r.visibilityACL(visibility)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.instance.PutObjectAcl(r.ctx, &s3.PutObjectAclInput{
+		Bucket: aws.String(r.bucket),
+		Key:    aws.String(file),
+		ACL:    types.ObjectCannedACL(acl),
+	}); err != nil {
+		return r.wrapError(file, err)
+	}
+
+	return nil
+}
+
+// Visibility gets the visibility of a file, it's public when the group granted by the public canned ACL can read it.
+func (r *S3) Visibility(file string) (string, error) {
+	resp, err := r.instance.GetObjectAcl(r.ctx, &s3.GetObjectAclInput{
+		Bucket: aws.String(r.bucket),
+		Key:    aws.String(file),
+	})
+	if err != nil {
+		return "", r.wrapError(file, err)
+	}
+
+	group := allUsersGroup
+	if r.visibility.public == string(types.ObjectCannedACLAuthenticatedRead) {
+		group = authenticatedUsersGroup
+	}
+	for _, grant := range resp.Grants {
+		if grant.Grantee == nil || aws.ToString(grant.Grantee.URI) != group {
+			continue
+		}
+		if grant.Permission == types.PermissionRead || grant.Permission == types.PermissionFullControl {
+			return VisibilityPublic, nil
+		}
+	}
+
+	return VisibilityPrivate, nil
+}
+
+// visibilityACL returns the canned ACL the visibility is mapped to.
+func (r *S3) visibilityACL(visibility string) (string, error) {
+	switch visibility {
+	case VisibilityPublic:
+		return r.visibility.public, nil
+	case VisibilityPrivate:
+		return r.visibility.private, nil
+	default:
+		return "", fmt.Errorf("unsupported visibility %q, it should be public or private", visibility)
+	}
+}
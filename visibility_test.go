@@ -0,0 +1,76 @@
+package s3
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVisibility(t *testing.T) {
+	driver, fake := newFakeDriver(t)
+
+	assert.Nil(t, driver.Put("Visibility/1.txt", "Goravel"))
+	visibility, err := driver.Visibility("Visibility/1.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, VisibilityPrivate, visibility)
+
+	assert.Nil(t, driver.SetVisibility("Visibility/1.txt", VisibilityPublic))
+	assert.Equal(t, "public-read", fake.objects["Visibility/1.txt"].acl)
+	visibility, err = driver.Visibility("Visibility/1.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, VisibilityPublic, visibility)
+
+	assert.Nil(t, driver.SetVisibility("Visibility/1.txt", VisibilityPrivate))
+	visibility, err = driver.Visibility("Visibility/1.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, VisibilityPrivate, visibility)
+
+	assert.EqualError(t, driver.SetVisibility("Visibility/1.txt", "hidden"), `unsupported visibility "hidden", it should be public or private`)
+	assert.ErrorIs(t, driver.SetVisibility("Visibility/2.txt", VisibilityPublic), ErrObjectNotFound)
+	_, err = driver.Visibility("Visibility/2.txt")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+}
+
+func TestVisibility_Put(t *testing.T) {
+	driver, fake := newFakeDriver(t, multipartConfigValues(), map[string]any{
+		"filesystems.disks.s3.object_canned_acl": "bucket-owner-full-control",
+	})
+
+	assert.Nil(t, driver.Put("Visibility/1.txt", "Goravel"))
+	assert.Equal(t, "bucket-owner-full-control", fake.objects["Visibility/1.txt"].acl)
+
+	// The visibility takes precedence over object_canned_acl.
+	assert.Nil(t, driver.PutWithOptions("Visibility/2.txt", "Goravel", WithVisibility(VisibilityPublic)))
+	assert.Equal(t, "public-read", fake.objects["Visibility/2.txt"].acl)
+
+	upload, err := driver.CreateUpload("Visibility/3.txt", WithVisibility(VisibilityPrivate))
+	assert.Nil(t, err)
+	assert.Nil(t, driver.UploadPart(upload, 1, strings.NewReader("Goravel")))
+	assert.Nil(t, driver.CompleteUpload(upload))
+	assert.Equal(t, "private", fake.objects["Visibility/3.txt"].acl)
+
+	assert.EqualError(t, driver.PutWithOptions("Visibility/4.txt", "Goravel", WithVisibility("hidden")), `unsupported visibility "hidden", it should be public or private`)
+	_, err = driver.CreateUpload("Visibility/4.txt", WithVisibility("hidden"))
+	assert.NotNil(t, err)
+	assert.NotContains(t, fake.keys(), "Visibility/4.txt")
+}
+
+func TestVisibility_Mapping(t *testing.T) {
+	driver, fake := newFakeDriver(t, map[string]any{
+		"filesystems.disks.s3.visibility.public":  "authenticated-read",
+		"filesystems.disks.s3.visibility.private": "bucket-owner-full-control",
+	})
+
+	assert.Nil(t, driver.PutWithOptions("Visibility/1.txt", "Goravel", WithVisibility(VisibilityPublic)))
+	assert.Equal(t, "authenticated-read", fake.objects["Visibility/1.txt"].acl)
+	visibility, err := driver.Visibility("Visibility/1.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, VisibilityPublic, visibility)
+
+	assert.Nil(t, driver.SetVisibility("Visibility/1.txt", VisibilityPrivate))
+	assert.Equal(t, "bucket-owner-full-control", fake.objects["Visibility/1.txt"].acl)
+	visibility, err = driver.Visibility("Visibility/1.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, VisibilityPrivate, visibility)
+}